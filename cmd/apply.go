@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/manifest"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply [manifest]",
+	Short: "Converge the environment to match a yerd.yaml manifest",
+	Long: `Read a manifest describing PHP versions and their extensions, the
+default CLI version, composer, the web components and sites, then make
+whatever changes are needed for the environment to match it.
+
+Nothing which is missing from the manifest is removed, so apply can be
+run again at any time.
+
+Example manifest:
+  php:
+    "8.3":
+      extensions: [mbstring, curl, pdo_mysql, redis]
+    "8.4": {}
+  cli: "8.4"
+  composer: true
+  web: true
+  sites:
+    - directory: ./       # relative to the manifest
+      domain: shop.test
+      php: "8.3"
+      aliases: [api.shop.test]
+
+Examples:
+  sudo yerd apply                   # Apply ./yerd.yaml
+  sudo yerd apply env/yerd.yaml     # Apply a specific manifest
+  yerd apply --dry-run              # Show the changes without making them`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runApply,
+}
+
+var applyDryRun bool
+
+func runApply(cmd *cobra.Command, args []string) error {
+	version.PrintSplash()
+
+	green := color.New(color.FgGreen)
+	blue := color.New(color.FgBlue)
+	red := color.New(color.FgRed)
+
+	path := "yerd.yaml"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	m, err := manifest.Load(path)
+	if err != nil {
+		return utils.ExitWithError(utils.ExitUsage, err)
+	}
+
+	changes, err := m.Plan()
+	if err != nil {
+		return utils.ExitWithError(utils.ExitFailure, err)
+	}
+
+	if len(changes) == 0 {
+		green.Println("✓ Environment already matches the manifest")
+		return nil
+	}
+
+	fmt.Printf("%d change(s) required:\n", len(changes))
+	for _, change := range changes {
+		blue.Printf("- %s\n", change.Description)
+	}
+	fmt.Println()
+
+	if applyDryRun {
+		return nil
+	}
+
+	if !utils.CheckAndPromptForSudo() {
+		return utils.Exit(utils.ExitPermission)
+	}
+
+	for i, change := range changes {
+		fmt.Printf("[%d/%d] %s\n", i+1, len(changes), change.Description)
+
+		if err := change.Apply(); err != nil {
+			red.Printf("❌ %s failed: %v\n", change.Description, err)
+			blue.Println("- Changes made before this point have been kept")
+			blue.Printf("- Run 'sudo yerd apply %s' again once the problem is resolved\n", path)
+			return utils.Reported(err)
+		}
+		fmt.Println()
+	}
+
+	green.Println("✓ Environment matches the manifest")
+	return nil
+}
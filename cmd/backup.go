@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/backup"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [archive]",
+	Short: "Export the YERD environment to a portable archive",
+	Long: `Write the YERD configuration, the php.ini, FPM and pool configuration of
+every PHP version and custom nginx snippets to a .tar.gz archive, which
+'yerd import' restores on another machine.
+
+The root CA private key is only included with --include-ca, anyone with
+the archive can then issue certificates your browsers trust.
+
+Examples:
+  yerd export                          # Write yerd-export-<date>.tar.gz
+  sudo yerd export ~/yerd.tar.gz --include-ca`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Restore a YERD environment from an archive made by 'yerd export'",
+	Long: `Rebuild every exported PHP version at its recorded version with its
+extensions and configuration, then install composer and the web
+components and recreate sites, parked directories, the DNS resolver and
+shims. Import requires a machine without YERD managed PHP or web
+components, except those left by an earlier import of the same archive:
+PHP versions already installed at their recorded version and existing
+sites are skipped, so a failed import can be run again to resume it.
+
+Examples:
+  sudo yerd import ~/yerd-export-2025-01-31.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+var exportIncludeCA bool
+
+func runExport(cmd *cobra.Command, args []string) error {
+	version.PrintSplash()
+
+	if exportIncludeCA && !utils.CheckAndPromptForSudo() {
+		return utils.Exit(utils.ExitPermission)
+	}
+
+	path := fmt.Sprintf("yerd-export-%s.tar.gz", time.Now().Format("2006-01-02"))
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	if err := backup.Export(path, exportIncludeCA); err != nil {
+		return utils.ExitWithError(utils.ExitFailure, err)
+	}
+
+	color.New(color.FgGreen).Printf("✓ Environment exported to %s\n", path)
+	if exportIncludeCA {
+		color.New(color.FgYellow).Println("⚠ The archive contains the root CA private key, keep it safe")
+	}
+
+	return nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	version.PrintSplash()
+
+	blue := color.New(color.FgBlue)
+	yellow := color.New(color.FgYellow)
+
+	if !utils.CheckAndPromptForSudo() {
+		return utils.Exit(utils.ExitPermission)
+	}
+
+	importer, err := backup.NewImporter(args[0])
+	if err != nil {
+		return utils.ExitWithError(utils.ExitUsage, err)
+	}
+
+	metadata := importer.Metadata()
+	fmt.Printf("Importing environment exported by YERD v%s on %s\n", metadata.YerdVersion, metadata.Created.Format("2006-01-02 15:04"))
+	if versions := importer.PhpVersions(); len(versions) > 0 {
+		blue.Printf("- PHP: %s\n", strings.Join(versions, ", "))
+	}
+	if sites := importer.Sites(); len(sites) > 0 {
+		blue.Printf("- Sites: %s\n", strings.Join(sites, ", "))
+	}
+	fmt.Println()
+
+	if err := importer.Import(); err != nil {
+		return utils.ExitWithError(utils.ExitFailure, err)
+	}
+
+	if warnings := importer.Warnings(); len(warnings) > 0 {
+		yellow.Printf("Import finished with %d warning(s):\n", len(warnings))
+		for _, warning := range warnings {
+			yellow.Printf("- %s\n", warning)
+		}
+		return utils.Exit(utils.ExitFailure)
+	}
+
+	color.New(color.FgGreen).Println("✓ Environment imported")
+	return nil
+}
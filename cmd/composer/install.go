@@ -13,7 +13,7 @@ func BuildInstallCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "install",
 		Short: "Installs a YERD managed Composer",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 			green := color.New(color.FgGreen)
 			yellow := color.New(color.FgYellow)
@@ -21,7 +21,7 @@ func BuildInstallCommand() *cobra.Command {
 			red := color.New(color.FgRed)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			if utils.FileExists(constants.LocalComposerPath) {
@@ -30,18 +30,19 @@ func BuildInstallCommand() *cobra.Command {
 				blue.Printf("- 'sudo yerd composer update'\n\n")
 
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			if err := internalComposer.InstallComposer(); err != nil {
 				red.Printf("Composer failed to install!\n")
 				blue.Printf("- Error: %v\n\n", err)
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			green.Printf("✓ Composer installed successfully\n")
 			blue.Printf("- Type it out with: 'composer --version'\n")
+			return nil
 		},
 	}
 }
@@ -13,7 +13,7 @@ func BuildUninstallCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "uninstall",
 		Short: "Uninstalls the YERD managed Composer",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 			green := color.New(color.FgGreen)
 			yellow := color.New(color.FgYellow)
@@ -21,7 +21,7 @@ func BuildUninstallCommand() *cobra.Command {
 			red := color.New(color.FgRed)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			if !utils.FileExists(constants.LocalComposerPath) {
@@ -30,17 +30,18 @@ func BuildUninstallCommand() *cobra.Command {
 				blue.Printf("- 'sudo yerd composer install'\n\n")
 
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			if err := internalComposer.RemoveComposer(); err != nil {
 				red.Printf("Composer failed to uninstall!\n")
 				blue.Printf("- Error: %v\n\n", err)
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			green.Printf("✓ Composer was uninstalled\n")
+			return nil
 		},
 	}
 }
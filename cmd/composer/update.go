@@ -13,7 +13,7 @@ func BuildUpdateCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "update",
 		Short: "Updates the YERD managed Composer to the latest version",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 			green := color.New(color.FgGreen)
 			yellow := color.New(color.FgYellow)
@@ -21,7 +21,7 @@ func BuildUpdateCommand() *cobra.Command {
 			red := color.New(color.FgRed)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			if !utils.FileExists(constants.LocalComposerPath) {
@@ -30,18 +30,19 @@ func BuildUpdateCommand() *cobra.Command {
 				blue.Printf("- 'sudo yerd composer install'\n\n")
 
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			if err := internalComposer.InstallComposer(); err != nil {
 				red.Printf("Composer failed to update!\n")
 				blue.Printf("- Error: %v\n\n", err)
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			green.Printf("✓ Composer updated successfully\n")
 			blue.Printf("- Type it out with: 'composer --version'\n")
+			return nil
 		},
 	}
 }
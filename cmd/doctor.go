@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the YERD installation for problems and optionally repair them",
+	Long: `Cross-check the YERD configuration against the filesystem, systemd,
+/etc/hosts and the system trust store, reporting every problem found.
+
+Checks include:
+- PHP binaries, symlinks, FPM services and sockets
+- Missing system libraries for installed extensions
+- Stale symlinks and services left by removed PHP versions
+- Nginx service and configuration, including files with no site
+- Site directories, certificates, hosts entries and pools
+- The YERD root CA and whether the system trusts it
+
+Examples:
+  yerd doctor            # Report problems
+  sudo yerd doctor --fix # Report and repair problems`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+var doctorFix bool
+
+// runDoctor reports every finding grouped by area, repairing the
+// fixable ones when --fix is given, the command fails while any error
+// remains
+func runDoctor(cmd *cobra.Command, args []string) error {
+	version.PrintSplash()
+
+	if doctorFix && !utils.CheckAndPromptForSudo() {
+		return utils.Exit(utils.ExitPermission)
+	}
+
+	findings := manager.Diagnose()
+	if len(findings) == 0 {
+		color.New(color.FgGreen).Println("✓ No problems found")
+		return nil
+	}
+
+	outputFindings(findings)
+
+	if doctorFix {
+		return fixFindings(findings)
+	}
+
+	fixable := 0
+	for _, finding := range findings {
+		if finding.Fix != nil {
+			fixable++
+		}
+	}
+
+	if fixable > 0 {
+		color.New(color.FgBlue).Printf("%d problem(s) can be repaired with 'sudo yerd doctor --fix'\n", fixable)
+	}
+
+	for _, finding := range findings {
+		if finding.Severity == manager.SeverityError {
+			return utils.Exit(utils.ExitFailure)
+		}
+	}
+
+	return nil
+}
+
+func outputFindings(findings []*manager.Finding) {
+	errors, warnings := 0, 0
+	area := ""
+
+	for _, finding := range findings {
+		if finding.Area != area {
+			if area != "" {
+				fmt.Println()
+			}
+			area = finding.Area
+			fmt.Printf("🩺 %s\n", area)
+		}
+
+		switch finding.Severity {
+		case manager.SeverityError:
+			errors++
+			color.New(color.FgRed).Printf("├─ ✗ %s", finding.Message)
+		case manager.SeverityWarning:
+			warnings++
+			color.New(color.FgYellow).Printf("├─ ⚠ %s", finding.Message)
+		default:
+			color.New(color.FgBlue).Printf("├─ ℹ %s", finding.Message)
+		}
+
+		if finding.Fix != nil {
+			fmt.Print(" (fixable)")
+		}
+		fmt.Println()
+
+		for _, line := range strings.Split(finding.Hint, "\n") {
+			if strings.TrimSpace(line) != "" {
+				fmt.Printf("│    %s\n", line)
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Found %d error(s) and %d warning(s)\n\n", errors, warnings)
+}
+
+func fixFindings(findings []*manager.Finding) error {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+
+	fixed, failed, unfixable := 0, 0, 0
+	for _, finding := range findings {
+		if finding.Fix == nil {
+			if finding.Severity == manager.SeverityError {
+				unfixable++
+			}
+			continue
+		}
+
+		if err := finding.Fix(); err != nil {
+			failed++
+			red.Printf("✗ %s: %s\n", finding.Area, finding.Message)
+			red.Printf("  - %v\n", err)
+			continue
+		}
+
+		fixed++
+		green.Printf("✓ %s: %s\n", finding.Area, finding.Message)
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		red.Printf("Repaired %d problem(s), %d could not be repaired\n", fixed, failed)
+		return utils.Exit(utils.ExitFailure)
+	}
+
+	green.Printf("Repaired %d problem(s)\n", fixed)
+
+	if unfixable > 0 {
+		red.Printf("%d error(s) need to be resolved manually\n", unfixable)
+		return utils.Exit(utils.ExitFailure)
+	}
+
+	return nil
+}
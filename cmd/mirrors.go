@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var mirrorsCmd = &cobra.Command{
+	Use:   "mirrors <list|set|reset|offline|online|clear> [source] [url]",
+	Short: "Manage where PHP, nginx and composer are downloaded from",
+	Long: `Every source YERD downloads can be redirected to a mirror, such as a
+corporate proxy or a local HTTP stand-in, which serves the same paths as
+the original. The sources are php (www.php.net), nginx (nginx.org) and
+composer (getcomposer.org).
+
+Downloads are kept in /opt/yerd/cache/sources and reused, so once an
+install has been done online it can be repeated in offline mode.
+
+The YERD_MIRROR_PHP, YERD_MIRROR_NGINX, YERD_MIRROR_COMPOSER and
+YERD_OFFLINE environment variables override the configuration for a
+single run.
+
+Examples:
+  yerd mirrors                                          # Show mirrors in use
+  sudo yerd mirrors set php https://php.mirror.corp     # Use a php.net mirror
+  sudo yerd mirrors reset php                           # Use www.php.net again
+  sudo yerd mirrors offline                             # Only use cached sources
+  sudo yerd mirrors online                              # Download missing sources
+  sudo yerd mirrors clear                               # Remove cached sources`,
+	ValidArgs: []string{"list", "set", "reset", "offline", "online", "clear"},
+	Args:      cobra.MaximumNArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version.PrintSplash()
+
+		green := color.New(color.FgGreen)
+		blue := color.New(color.FgBlue)
+		red := color.New(color.FgRed)
+
+		action := "list"
+		if len(args) > 0 {
+			action = args[0]
+		}
+
+		if action != "list" && !utils.CheckAndPromptForSudo() {
+			return utils.Exit(utils.ExitPermission)
+		}
+
+		mirrors := config.GetMirrors()
+
+		switch action {
+		case "list":
+			outputMirrors()
+			return nil
+
+		case "set", "reset":
+			if len(args) < 2 || (action == "set" && len(args) < 3) {
+				red.Println("Error: usage is 'yerd mirrors set <source> <url>' or 'yerd mirrors reset <source>'")
+				return utils.Exit(utils.ExitUsage)
+			}
+
+			url := ""
+			if action == "set" {
+				url = args[2]
+			}
+
+			if !setMirror(&mirrors, args[1], url) {
+				red.Printf("Error: Unknown source '%s', use 'php', 'nginx' or 'composer'\n", args[1])
+				return utils.Exit(utils.ExitUsage)
+			}
+
+			if err := config.SetStruct("mirrors", mirrors); err != nil {
+				return utils.ExitWithError(utils.ExitFailure, err)
+			}
+
+			if url == "" {
+				green.Printf("✓ %s will be downloaded from its default location\n", args[1])
+			} else {
+				green.Printf("✓ %s will be downloaded from %s\n", args[1], url)
+			}
+
+		case "offline", "online":
+			mirrors.Offline = action == "offline"
+			if err := config.SetStruct("mirrors", mirrors); err != nil {
+				return utils.ExitWithError(utils.ExitFailure, err)
+			}
+
+			green.Printf("✓ YERD is now %s\n", action)
+
+		case "clear":
+			if err := utils.ClearSourceCache(); err != nil {
+				red.Println("❌ Error: Unable to clear the source cache")
+				blue.Printf("- %v\n\n", err)
+				return utils.Exit(utils.ExitFailure)
+			}
+
+			green.Println("✓ Source cache cleared")
+
+		default:
+			red.Printf("Error: Invalid action '%s'. Use 'list', 'set', 'reset', 'offline', 'online' or 'clear'\n", action)
+			return utils.Exit(utils.ExitUsage)
+		}
+
+		return nil
+	},
+}
+
+// setMirror changes the mirror of a single source, returning false when
+// the source is not known
+func setMirror(mirrors *utils.Mirrors, source, url string) bool {
+	switch source {
+	case "php":
+		mirrors.Php = url
+	case "nginx":
+		mirrors.Nginx = url
+	case "composer":
+		mirrors.Composer = url
+	default:
+		return false
+	}
+
+	return true
+}
+
+// outputMirrors prints the mirrors in use, including any overridden by
+// environment variables
+func outputMirrors() {
+	mirrors := utils.GetMirrors()
+
+	sources := []struct {
+		name, mirror, base string
+	}{
+		{"php", mirrors.Php, utils.PhpSourceBase},
+		{"nginx", mirrors.Nginx, utils.NginxSourceBase},
+		{"composer", mirrors.Composer, utils.ComposerSourceBase},
+	}
+
+	fmt.Printf("🌐 Source Mirrors\n")
+	for _, source := range sources {
+		location := source.base + " (default)"
+		if source.mirror != "" {
+			location = source.mirror
+		}
+
+		fmt.Printf("├─ %s: %s\n", source.name, location)
+	}
+
+	offline := "No"
+	if mirrors.Offline {
+		offline = "Yes"
+	}
+
+	fmt.Printf("├─ Offline: %s\n", offline)
+	fmt.Printf("└─ Cache: %s\n\n", constants.SourceCacheDir)
+}
@@ -1,11 +1,119 @@
 package cmd
 
 import (
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/cmd/php"
+	"github.com/lumosolutions/yerd/internal/constants"
+	phpinstaller "github.com/lumosolutions/yerd/internal/installers/php"
+	"github.com/lumosolutions/yerd/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 var phpCmd = &cobra.Command{
-	Use:   "php",
+	Use:   "php <version> <command>",
 	Short: "Manage PHP versions",
-	Long:  `Install, remove, update, and manage multiple PHP versions on your system.`,
+	Long: `Install, remove, update, and manage multiple PHP versions on your system.
+
+Each PHP version is managed with 'yerd php <version> <command>', the
+versions available are those supported by php.net, the legacy 7.4 and 8.0
+release lines, and any release line with a pre-release under test.`,
+	// Flags belong to the version's own commands, which are only built
+	// once the version is known, so they are parsed by runPhpVersion
+	DisableFlagParsing: true,
+	Args:               cobra.ArbitraryArgs,
+	ValidArgsFunction:  completePhpVersion,
+	RunE:               runPhpVersion,
+}
+
+// runPhpVersion runs 'yerd php <version> <command>' through the commands
+// of the version, the catalogue is refreshed first when it does not know
+// the version, eg: a new pre-release
+func runPhpVersion(cmd *cobra.Command, args []string) error {
+	position := phpVersionArg(cmd, args)
+	if position < 0 {
+		return cmd.Help()
+	}
+
+	version := args[position]
+	if constants.IsPhpVersionFormat(version) && !constants.IsValidPhpVersion(version) {
+		if _, err := phpinstaller.RefreshCatalogue(true); err != nil {
+			utils.LogWarning("catalogue", "Unable to refresh the catalogue: %v", err)
+		}
+	}
+
+	if !constants.IsValidPhpVersion(version) {
+		red := color.New(color.FgRed)
+		red.Printf("Error: PHP %s is not available, use one of: %s\n", version, strings.Join(constants.GetAvailablePhpVersions(), ", "))
+		return utils.Exit(utils.ExitUsage)
+	}
+
+	versionCmd := php.CreateVersionCommand(version)
+	versionCmd.Annotations = map[string]string{
+		cobra.CommandDisplayNameAnnotation: cmd.CommandPath() + " " + version,
+	}
+
+	// The root setup has already run, so the version command is executed
+	// on its own, only the global flags given around it are applied
+	versionCmd.PersistentFlags().AddFlagSet(cmd.Root().PersistentFlags())
+	versionCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return applyGlobalFlags(cmd)
+	}
+	versionCmd.CompletionOptions.DisableDefaultCmd = true
+	versionCmd.SilenceErrors = true
+	versionCmd.SetArgs(slices.Delete(slices.Clone(args), position, position+1))
+
+	_, err := versionCmd.ExecuteC()
+	return err
+}
+
+// phpVersionArg returns the position of the version in args, skipping
+// global flags and their values, or -1 when no version was given
+func phpVersionArg(cmd *cobra.Command, args []string) int {
+	flags := cmd.InheritedFlags()
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return i
+		}
+
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		flag := flags.Lookup(strings.TrimPrefix(arg, "--"))
+		if !strings.HasPrefix(arg, "--") {
+			flag = flags.ShorthandLookup(strings.TrimPrefix(arg, "-"))
+		}
+
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+
+	return -1
+}
+
+// completePhpVersion completes the version, then the commands of the
+// version once it has been given
+func completePhpVersion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	phpinstaller.LoadCatalogue()
+
+	if len(args) == 0 {
+		return constants.GetAvailablePhpVersions(), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if len(args) > 1 || !slices.Contains(constants.GetAvailablePhpVersions(), args[0]) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	commands := []string{}
+	for _, command := range php.CreateVersionCommand(args[0]).Commands() {
+		commands = append(commands, command.Name()+"\t"+command.Short)
+	}
+
+	return commands, cobra.ShellCompDirectiveNoFileComp
 }
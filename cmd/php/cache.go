@@ -0,0 +1,135 @@
+package php
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/config"
+	phpinstaller "github.com/lumosolutions/yerd/internal/installers/php"
+	"github.com/lumosolutions/yerd/internal/utils"
+	intVersion "github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func BuildCacheCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cache <list|clear|enable|disable|mirror> [location]",
+		Short: "Manage the cache of compiled PHP builds",
+		Long: `Every compiled PHP version is stored in /opt/yerd/cache, keyed by the
+PHP version, extensions, distribution and architecture, so rebuilding or
+reinstalling the same build is restored in seconds instead of compiled.
+
+A mirror shares builds within a team, builds missing locally are fetched
+from it, and new builds are copied to it when it is a directory. Every
+build has a <name>.sha256 checksum file next to it, builds which do not
+match their checksum are dropped and compiled from source instead.
+
+Examples:
+  yerd php cache list                                    # Show cached builds
+  sudo yerd php cache clear                              # Remove cached builds
+  sudo yerd php cache disable                            # Always compile from source
+  sudo yerd php cache mirror /mnt/team/yerd              # Share builds via a directory
+  sudo yerd php cache mirror https://builds.example.com  # Fetch builds over HTTP
+  sudo yerd php cache mirror ""                          # Stop using a mirror`,
+		ValidArgs: []string{"list", "clear", "enable", "disable", "mirror"},
+		Args:      cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			intVersion.PrintSplash()
+
+			green := color.New(color.FgGreen)
+			blue := color.New(color.FgBlue)
+			red := color.New(color.FgRed)
+
+			action := "list"
+			if len(args) > 0 {
+				action = args[0]
+			}
+
+			if action != "list" && !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			cacheConfig := config.GetBuildCacheConfig()
+
+			switch action {
+			case "list":
+				return outputBuildCache(cacheConfig)
+
+			case "clear":
+				if err := phpinstaller.ClearBuildCache(); err != nil {
+					red.Println("❌ Error: Unable to clear the build cache")
+					blue.Printf("- %v\n\n", err)
+					return utils.Exit(utils.ExitFailure)
+				}
+
+				green.Println("✓ Build cache cleared")
+
+			case "enable", "disable":
+				cacheConfig.Disabled = action == "disable"
+				if err := config.SetStruct("build_cache", cacheConfig); err != nil {
+					return utils.ExitWithError(utils.ExitFailure, err)
+				}
+
+				green.Printf("✓ Build cache %sd\n", action)
+
+			case "mirror":
+				if len(args) < 2 {
+					red.Println("Error: requires a directory or URL, or \"\" to remove the mirror")
+					return utils.Exit(utils.ExitUsage)
+				}
+
+				cacheConfig.Mirror = args[1]
+				if err := config.SetStruct("build_cache", cacheConfig); err != nil {
+					return utils.ExitWithError(utils.ExitFailure, err)
+				}
+
+				if cacheConfig.Mirror == "" {
+					green.Println("✓ Build cache mirror removed")
+				} else {
+					green.Printf("✓ Build cache mirror set to %s\n", cacheConfig.Mirror)
+				}
+
+			default:
+				red.Printf("Error: Invalid action '%s'. Use 'list', 'clear', 'enable', 'disable' or 'mirror'\n", action)
+				return utils.Exit(utils.ExitUsage)
+			}
+
+			return nil
+		},
+	}
+}
+
+func outputBuildCache(cacheConfig *config.BuildCacheConfig) error {
+	builds, err := phpinstaller.ListCachedBuilds()
+	if err != nil {
+		return utils.ExitWithError(utils.ExitFailure, err)
+	}
+
+	mirror := cacheConfig.Mirror
+	if mirror == "" {
+		mirror = "None"
+	}
+
+	fmt.Printf("📦 PHP Build Cache\n")
+	fmt.Printf("├─ Enabled: %s\n", friendlyBool(!cacheConfig.Disabled))
+	fmt.Printf("├─ Mirror: %s\n", mirror)
+
+	if len(builds) == 0 {
+		fmt.Printf("└─ Builds: None\n\n")
+		return nil
+	}
+
+	fmt.Printf("└─ Builds:\n")
+	for i, build := range builds {
+		branch, indent := "├─", "│ "
+		if i == len(builds)-1 {
+			branch, indent = "└─", "  "
+		}
+
+		fmt.Printf("   %s PHP %s, %.1f MB, %s\n", branch, build.Version, float64(build.Size)/1024/1024, build.Modified.Format("2006-01-02 15:04"))
+		fmt.Printf("   %s  %s\n", indent, build.Name)
+	}
+	fmt.Println()
+
+	return nil
+}
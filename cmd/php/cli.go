@@ -19,11 +19,11 @@ func buildCliCmd(version string) *cobra.Command {
 		
 Examples:
   yerd php 8.4 cli`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			force, _ := cmd.Flags().GetBool("force")
@@ -36,7 +36,7 @@ Examples:
 				red.Println("❌ Error: No action taken")
 				blue.Printf("- PHP %s is not installed, please use\n", version)
 				blue.Printf("- 'sudo yerd php %s install'\n\n", version)
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			if data.IsCLI && !force {
@@ -44,7 +44,7 @@ Examples:
 				blue.Printf("- PHP %s is already the default CLI version of PHP\n", version)
 				blue.Println("- If you wish to reapply this version forceably, you can use:")
 				blue.Printf("- 'sudo yerd php %s cli -f'\n\n", version)
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			fmt.Printf("Setting PHP %s as the default CLI version\n", version)
@@ -53,7 +53,7 @@ Examples:
 				red.Println("❌ Error: No action taken")
 				blue.Printf("- Unable to set PHP %s as the default CLI version", version)
 				blue.Printf("- %v", err)
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			green.Println("✓ Default PHP CLI version has been updated")
@@ -63,6 +63,7 @@ Examples:
 				blue.Print("- update was forced using the -f/--force flag")
 			}
 
+			return nil
 		},
 	}
 
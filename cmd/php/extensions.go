@@ -25,7 +25,7 @@ Examples:
 			version, version, version, version, version,
 		),
 		ValidArgs: []string{"add", "remove"},
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 
 			blue := color.New(color.FgBlue)
@@ -34,7 +34,7 @@ Examples:
 			if len(args) < 1 {
 				red.Println("Error: requires at least 1 argument: <list|add|remove>")
 				cmd.Usage()
-				return
+				return utils.Exit(utils.ExitUsage)
 			}
 
 			action := args[0]
@@ -42,7 +42,7 @@ Examples:
 			if len(args) < 2 && action != "list" {
 				red.Printf("Error: requires at least 2 arguments: %s <extensions>\n", action)
 				cmd.Usage()
-				return
+				return utils.Exit(utils.ExitUsage)
 			}
 
 			extensions := args[1:]
@@ -53,7 +53,7 @@ Examples:
 
 			if rebuild {
 				if !utils.CheckAndPromptForSudo() {
-					return
+					return utils.Exit(utils.ExitPermission)
 				}
 			}
 
@@ -62,13 +62,15 @@ Examples:
 				red.Println("❌ Error: No action taken")
 				blue.Printf("- PHP %s is not installed, please use\n", version)
 				blue.Printf("- 'sudo yerd php %s install'\n\n", version)
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			extManager := phpinstaller.NewExtensionManager(version, data, nocache, configFlag, rebuild)
 			if err := extManager.RunAction(action, extensions); err != nil {
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
+
+			return nil
 		},
 	}
 
@@ -15,11 +15,11 @@ func buildInstallCmd(version string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "install",
 		Short: fmt.Sprintf("Install PHP %s", version),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			blue := color.New(color.FgBlue)
@@ -32,7 +32,7 @@ func buildInstallCmd(version string) *cobra.Command {
 				blue.Printf("- 'sudo yerd php %s rebuild' to build the current version\n", version)
 				blue.Printf("- 'sudo yerd php %s upgrade' to update PHP %s to the latest version\n\n", version, version)
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			nocache, _ := cmd.Flags().GetBool("nocache")
@@ -40,16 +40,17 @@ func buildInstallCmd(version string) *cobra.Command {
 			installer, err := phpinstaller.NewPhpInstaller(version, nocache, true)
 			if err != nil {
 				red.Printf("Failed to install php%s: %v\n", version, err)
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			if err := installer.Install(); err != nil {
 				red.Printf("Failed to install php%s: %v\n", version, err)
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			green.Println("✓ Installation complete...")
 			fmt.Println("Thanks for using YERD")
+			return nil
 		},
 	}
 
@@ -3,25 +3,37 @@ package php
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/lumosolutions/yerd/internal/config"
 	"github.com/lumosolutions/yerd/internal/constants"
 	phpinstaller "github.com/lumosolutions/yerd/internal/installers/php"
+	"github.com/lumosolutions/yerd/internal/utils"
 	intVersion "github.com/lumosolutions/yerd/internal/version"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
 func BuildListCmd() *cobra.Command {
+	var available bool
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "Lists installed PHP versions",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 
 			versions := constants.GetAvailablePhpVersions()
 			latestVersions, _, _ := phpinstaller.GetLatestVersions()
 
+			if available {
+				return outputAvailable(versions, latestVersions)
+			}
+
+			if utils.IsStructuredOutput() {
+				return outputStructuredList(versions, latestVersions)
+			}
+
 			rows := [][]string{}
 			headers := []string{"VERSION", "INSTALLED", "CLI", "EXTENSIONS", "UPDATES"}
 
@@ -40,7 +52,7 @@ func BuildListCmd() *cobra.Command {
 			if len(rows) == 0 {
 				fmt.Println("No YERD PHP versions installed")
 				fmt.Println("Run 'sudo yerd php {version} install' to get started")
-				return
+				return nil
 			}
 
 			table := tablewriter.NewWriter(os.Stdout)
@@ -48,12 +60,100 @@ func BuildListCmd() *cobra.Command {
 			table.Bulk(rows)
 
 			table.Render()
+			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&available, "available", false, "List every PHP version which can be installed")
+
 	return cmd
 }
 
+type availableOutput struct {
+	Version       string `json:"version" yaml:"version"`
+	LatestVersion string `json:"latest_version" yaml:"latest_version"`
+	Status        string `json:"status" yaml:"status"`
+	Installed     bool   `json:"installed" yaml:"installed"`
+}
+
+// outputAvailable lists every release line in the catalogue, marking
+// those php.net no longer supports, those only available as a
+// pre-release and those which cannot be built until a patch is added
+func outputAvailable(versions []string, latestVersions map[string]string) error {
+	catalogue := phpinstaller.GetCatalogue()
+
+	list := []availableOutput{}
+	unavailable := []string{}
+	for _, version := range versions {
+		data, installed := config.GetInstalledPhpInfo(version)
+
+		extensions := constants.GetDefaultExtensions()
+		if installed {
+			extensions = data.Extensions
+		}
+
+		status := "supported"
+		if missing, _ := phpinstaller.MissingPatches(version, extensions); len(missing) > 0 {
+			status = "unavailable"
+			unavailable = append(unavailable, fmt.Sprintf("PHP %s needs a patch in %s to build %s", version, phpinstaller.PatchDirectory(version), strings.Join(missing, ", ")))
+		} else if _, prerelease := catalogue.Prereleases[version]; prerelease && !catalogue.IsSupported(version) {
+			status = "pre-release"
+		} else if constants.GetPhpProfile(version).Legacy || (len(catalogue.Supported) > 0 && !catalogue.IsSupported(version)) {
+			status = "legacy"
+		}
+
+		list = append(list, availableOutput{
+			Version:       version,
+			LatestVersion: latestVersions[version],
+			Status:        status,
+			Installed:     installed,
+		})
+	}
+
+	if utils.IsStructuredOutput() {
+		return utils.PrintStructured(list)
+	}
+
+	rows := [][]string{}
+	for _, item := range list {
+		rows = append(rows, []string{item.Version, item.LatestVersion, item.Status, friendlyBool(item.Installed)})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"VERSION", "LATEST", "STATUS", "INSTALLED"})
+	table.Bulk(rows)
+
+	table.Render()
+
+	for _, note := range unavailable {
+		fmt.Printf("- %s\n", note)
+	}
+
+	return nil
+}
+
+type listOutput struct {
+	phpVersionOutput `yaml:",inline"`
+	LatestVersion    string `json:"latest_version" yaml:"latest_version"`
+	UpdateAvailable  bool   `json:"update_available" yaml:"update_available"`
+}
+
+func outputStructuredList(versions []string, latestVersions map[string]string) error {
+	list := []listOutput{}
+
+	for _, version := range versions {
+		if data, installed := config.GetInstalledPhpInfo(version); installed {
+			list = append(list, listOutput{
+				phpVersionOutput: newPhpVersionOutput(*data),
+				LatestVersion:    latestVersions[version],
+				UpdateAvailable:  latestVersions[version] != "" && data.InstalledVersion != latestVersions[version],
+			})
+		}
+	}
+
+	return utils.PrintStructured(list)
+}
+
 func friendlyBool(value bool) string {
 	if value {
 		return "Yes"
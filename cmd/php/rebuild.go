@@ -14,11 +14,11 @@ func buildRebuildCmd(version string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "rebuild",
 		Short: fmt.Sprintf("Rebuild PHP %s", version),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			data, installed := config.GetInstalledPhpInfo(version)
@@ -26,7 +26,7 @@ func buildRebuildCmd(version string) *cobra.Command {
 				fmt.Printf("PHP %s is not installed, please use\n", version)
 				fmt.Printf("   'sudo yerd php %s install' instead\n\n", version)
 				fmt.Println("Thanks for using YERD")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			nocache, _ := cmd.Flags().GetBool("nocache")
@@ -34,8 +34,10 @@ func buildRebuildCmd(version string) *cobra.Command {
 
 			if err := phpinstaller.RunRebuild(data, nocache, configFlag); err != nil {
 				fmt.Printf("Failed to rebuild php%s: %v\n", version, err)
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
+
+			return nil
 		},
 	}
 
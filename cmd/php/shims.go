@@ -0,0 +1,152 @@
+package php
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/installers/shim"
+	"github.com/lumosolutions/yerd/internal/utils"
+	intVersion "github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func BuildShimsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "shims <enable|disable|status>",
+		Short: "Resolve the php and composer version per project",
+		Long: `Replace the global php and composer commands with shims which pick the
+PHP version from a .php-version or .yerd.json file, found by walking up
+from the current directory, falling back to the default CLI version.
+
+Examples:
+  sudo yerd php shims enable     # Use per-project PHP versions
+  sudo yerd php shims disable    # Restore the global CLI symlinks
+  yerd php shims status          # Show the version used in this directory`,
+		ValidArgs: []string{"enable", "disable", "status"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			intVersion.PrintSplash()
+
+			green := color.New(color.FgGreen)
+			blue := color.New(color.FgBlue)
+			red := color.New(color.FgRed)
+
+			action := "status"
+			if len(args) > 0 {
+				action = args[0]
+			}
+
+			switch action {
+			case "enable":
+				if !utils.CheckAndPromptForSudo() {
+					return utils.Exit(utils.ExitPermission)
+				}
+
+				if err := shim.Enable(); err != nil {
+					red.Println("❌ Error: Unable to enable shims")
+					blue.Printf("- %v\n\n", err)
+					return utils.Exit(utils.ExitFailure)
+				}
+
+				green.Println("✓ PHP shims enabled")
+				blue.Printf("- Pin a project with 'yerd php pin <version>'\n")
+				blue.Printf("- Or add {\"php\": \"8.3\"} to %s\n", shim.ProjectConfigFile)
+
+			case "disable":
+				if !utils.CheckAndPromptForSudo() {
+					return utils.Exit(utils.ExitPermission)
+				}
+
+				if err := shim.Disable(); err != nil {
+					red.Println("❌ Error: Unable to disable shims")
+					blue.Printf("- %v\n\n", err)
+					return utils.Exit(utils.ExitFailure)
+				}
+
+				green.Println("✓ PHP shims disabled")
+				blue.Println("- php and composer now follow the default CLI version")
+
+			case "status":
+				outputShimStatus()
+
+			default:
+				red.Printf("Error: Invalid action '%s'. Use 'enable', 'disable' or 'status'\n", action)
+				return utils.Exit(utils.ExitUsage)
+			}
+
+			return nil
+		},
+	}
+}
+
+func BuildPinCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin <version>",
+		Short: "Pin the PHP version for the current directory",
+		Long: `Writes a .php-version file into the current directory, used by the
+php and composer shims to select the PHP version for this project.
+
+Examples:
+  yerd php pin 8.3`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			intVersion.PrintSplash()
+
+			green := color.New(color.FgGreen)
+			yellow := color.New(color.FgYellow)
+			blue := color.New(color.FgBlue)
+			red := color.New(color.FgRed)
+
+			version := args[0]
+			if !constants.IsValidPhpVersion(version) {
+				red.Printf("Error: PHP %s is not available, use one of: %s\n", version, strings.Join(constants.GetAvailablePhpVersions(), ", "))
+				return utils.Exit(utils.ExitUsage)
+			}
+
+			dir, err := utils.GetWorkingDirectory()
+			if err != nil {
+				red.Println("❌ Error: Unable to determine the current directory")
+				return utils.Exit(utils.ExitFailure)
+			}
+
+			path, err := shim.Pin(dir, version)
+			if err != nil {
+				red.Println("❌ Error: Unable to pin PHP version")
+				blue.Printf("- %v\n\n", err)
+				return utils.Exit(utils.ExitFailure)
+			}
+
+			green.Printf("✓ Pinned PHP %s\n", version)
+			blue.Printf("- Written to %s\n", path)
+
+			if _, installed := config.GetInstalledPhpInfo(version); !installed {
+				yellow.Printf("- PHP %s is not installed yet, use 'sudo yerd php %s install'\n", version, version)
+			}
+
+			if !config.GetShimConfig().Enabled {
+				yellow.Println("- Shims are disabled, enable them with 'sudo yerd php shims enable'")
+			}
+
+			return nil
+		},
+	}
+}
+
+func outputShimStatus() {
+	enabled := config.GetShimConfig().Enabled
+
+	fmt.Printf("🔀 YERD PHP Shims\n")
+	fmt.Printf("├─ Enabled: %s\n", friendlyBool(enabled))
+
+	dir, _ := utils.GetWorkingDirectory()
+	resolution, err := shim.Resolve(dir)
+	if err != nil {
+		fmt.Printf("└─ Resolved: None (%v)\n\n", err)
+		return
+	}
+
+	fmt.Printf("├─ Resolved: PHP %s\n", resolution.Version)
+	fmt.Printf("└─ Source: %s\n\n", resolution.Source)
+}
@@ -14,17 +14,23 @@ func BuildStatusCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "status",
 		Short: "Show YERD PHP status and configuration",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			version.PrintSplash()
 
 			all := make(config.PhpConfig)
 			config.GetStruct("php", &all)
 
+			if utils.IsStructuredOutput() {
+				return outputStructuredStatus(all)
+			}
+
 			outputYerdConfig(all)
 
 			for _, version := range all {
 				outputPhpInfo(version)
 			}
+
+			return nil
 		},
 	}
 }
@@ -71,3 +77,68 @@ func getServiceStatus(version string) string {
 	}
 	return "Stopped"
 }
+
+// phpVersionOutput is the machine readable form of an installed PHP version
+type phpVersionOutput struct {
+	Version          string    `json:"version" yaml:"version"`
+	InstalledVersion string    `json:"installed_version" yaml:"installed_version"`
+	CLI              bool      `json:"cli" yaml:"cli"`
+	Binary           string    `json:"binary" yaml:"binary"`
+	PhpIni           string    `json:"php_ini" yaml:"php_ini"`
+	Extensions       []string  `json:"extensions" yaml:"extensions"`
+	FPM              fpmOutput `json:"fpm" yaml:"fpm"`
+}
+
+type fpmOutput struct {
+	Service string `json:"service" yaml:"service"`
+	Socket  string `json:"socket" yaml:"socket"`
+	Running bool   `json:"running" yaml:"running"`
+}
+
+type statusOutput struct {
+	CLIVersion string             `json:"cli_version" yaml:"cli_version"`
+	Config     string             `json:"config" yaml:"config"`
+	Versions   []phpVersionOutput `json:"versions" yaml:"versions"`
+}
+
+func outputStructuredStatus(all config.PhpConfig) error {
+	status := statusOutput{
+		Config:   "~/.config/yerd/config.json",
+		Versions: []phpVersionOutput{},
+	}
+
+	for _, version := range constants.GetAvailablePhpVersions() {
+		info, installed := all[version]
+		if !installed {
+			continue
+		}
+
+		if info.IsCLI {
+			status.CLIVersion = info.Version
+		}
+		status.Versions = append(status.Versions, newPhpVersionOutput(info))
+	}
+
+	return utils.PrintStructured(status)
+}
+
+func newPhpVersionOutput(info config.PhpInfo) phpVersionOutput {
+	extensions := info.Extensions
+	if extensions == nil {
+		extensions = []string{}
+	}
+
+	return phpVersionOutput{
+		Version:          info.Version,
+		InstalledVersion: info.InstalledVersion,
+		CLI:              info.IsCLI,
+		Binary:           constants.YerdBinDir + fmt.Sprintf("/php%s", info.Version),
+		PhpIni:           constants.YerdEtcDir + fmt.Sprintf("/php%s/php.ini", info.Version),
+		Extensions:       extensions,
+		FPM: fpmOutput{
+			Service: fmt.Sprintf("yerd-php%s-fpm", info.Version),
+			Socket:  constants.FPMSockDir + fmt.Sprintf("/php%s-fpm.sock", info.Version),
+			Running: getServiceStatus(info.Version) == "Running",
+		},
+	}
+}
@@ -2,8 +2,6 @@ package php
 
 import (
 	"fmt"
-	"slices"
-	"strings"
 
 	"github.com/fatih/color"
 	"github.com/lumosolutions/yerd/internal/config"
@@ -17,11 +15,11 @@ func buildUninstallCmd(version string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "uninstall",
 		Short: fmt.Sprintf("Uninstalls PHP %s", version),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			green := color.New(color.FgGreen)
@@ -29,26 +27,21 @@ func buildUninstallCmd(version string) *cobra.Command {
 			blue := color.New(color.FgBlue)
 			red := color.New(color.FgRed)
 
-			agree, _ := cmd.Flags().GetBool("yes")
+			agree, _ := cmd.Flags().GetBool("agree")
 
 			data, installed := config.GetInstalledPhpInfo(version)
 			if !installed {
 				red.Println("❌ Error: No action taken")
 				blue.Printf("- PHP %s is not installed\n\n", version)
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			if data.IsCLI && !agree {
 				yellow.Printf("⚠️  Warning: PHP %s is currently set as CLI version\n", version)
 				fmt.Printf("This will remove the PHP CLI and the 'php' command will no longer work.\n")
-				fmt.Printf("Continue? (y/N): ")
 
-				var response string
-				fmt.Scanln(&response)
-
-				if !isYes(response) {
-					red.Printf("\n❌ Operation cancelled\n")
-					return
+				if err := confirmUninstall("Continue?"); err != nil {
+					return err
 				}
 
 				fmt.Println()
@@ -56,15 +49,10 @@ func buildUninstallCmd(version string) *cobra.Command {
 
 			if !agree {
 				yellow.Printf("⚠️  Are you sure you want to uninstall PHP %s?\n", version)
-				fmt.Printf("Confirm Action (y/N): ")
-			}
-
-			var response string
-			fmt.Scanln(&response)
 
-			if !isYes(response) {
-				red.Printf("\n❌ Operation cancelled\n")
-				return
+				if err := confirmUninstall("Confirm Action"); err != nil {
+					return err
+				}
 			}
 
 			fmt.Printf("Removing PHP %s\n", version)
@@ -73,11 +61,11 @@ func buildUninstallCmd(version string) *cobra.Command {
 				red.Println("❌ Error: No action taken")
 				blue.Printf("- Unable to uninstall PHP %s\n", version)
 				blue.Printf("- %v\n\n", err)
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			green.Printf("✓ PHP %s has been uninstalled\n\n", version)
-
+			return nil
 		},
 	}
 
@@ -86,11 +74,21 @@ func buildUninstallCmd(version string) *cobra.Command {
 	return cmd
 }
 
-func isYes(value string) bool {
-	valid := []string{"y", "yes"}
+// confirmUninstall prompts the user, returning an error when they decline
+// or when a prompt is not possible
+func confirmUninstall(question string) error {
+	red := color.New(color.FgRed)
+
+	confirmed, err := utils.Confirm(question)
+	if err != nil {
+		red.Printf("❌ Operation cancelled, use --agree to uninstall non-interactively\n")
+		return utils.ExitWithError(utils.ExitInteractionRequired, err)
+	}
+
+	if !confirmed {
+		red.Printf("\n❌ Operation cancelled\n")
+		return utils.Exit(utils.ExitFailure)
+	}
 
-	return slices.Contains(
-		valid,
-		strings.ToLower(strings.TrimSpace(value)),
-	)
+	return nil
 }
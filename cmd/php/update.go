@@ -15,11 +15,11 @@ func buildUpdateCmd(version string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: fmt.Sprintf("Update PHP %s", version),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			intVersion.PrintSplash()
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			blue := color.New(color.FgBlue)
@@ -32,7 +32,7 @@ func buildUpdateCmd(version string) *cobra.Command {
 				yellow.Printf("PHP %s is not installed, please use the following command:\n", version)
 				blue.Printf("- 'sudo yerd php %s install'\n\n", version)
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			configFlag, _ := cmd.Flags().GetBool("config")
@@ -40,7 +40,7 @@ func buildUpdateCmd(version string) *cobra.Command {
 			if err != nil {
 				phpinstaller.PrintVersionFetchError(version)
 				red.Printf("\n❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			if versions[version] == data.InstalledVersion {
@@ -51,24 +51,25 @@ func buildUpdateCmd(version string) *cobra.Command {
 				blue.Printf("- 'sudo yerd php %s rebuild\n\n", version)
 
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			installer, err := phpinstaller.NewPhpInstaller(version, true, configFlag)
 			if err != nil {
 				red.Printf("Failed to upgrade PHP %s: %v\n", version, err)
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			if err := installer.Install(); err != nil {
 				red.Printf("Failed to upgrade PHP %s: %v\n", version, err)
 				red.Printf("❌ Operation cancelled\n")
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			green.Println("✓ PHP has been upgraded to the latest version successfully...")
 			fmt.Println("Thanks for using YERD")
+			return nil
 		},
 	}
 
@@ -8,7 +8,9 @@ import (
 	"github.com/lumosolutions/yerd/cmd/php"
 	"github.com/lumosolutions/yerd/cmd/sites"
 	"github.com/lumosolutions/yerd/cmd/web"
-	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/config"
+	phpinstaller "github.com/lumosolutions/yerd/internal/installers/php"
+	"github.com/lumosolutions/yerd/internal/utils"
 	"github.com/lumosolutions/yerd/internal/version"
 	"github.com/spf13/cobra"
 )
@@ -22,27 +24,68 @@ var rootCmd = &cobra.Command{
   • Lightweight and fast - no unnecessary overhead
   • Developer friendly`,
 	Version: version.GetVersion(),
-	Run: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyGlobalFlags(cmd); err != nil {
+			return err
+		}
+
+		utils.SetMirrors(config.GetMirrors())
+		phpinstaller.LoadCatalogue()
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		version.PrintSplash()
 		cmd.Help()
+		return nil
 	},
 }
 
-// Execute runs the root command and handles any errors using cobra's error handler.
+var (
+	outputFormat   string
+	nonInteractive bool
+)
+
+// applyGlobalFlags applies the --output and --non-interactive flags
+func applyGlobalFlags(cmd *cobra.Command) error {
+	if err := utils.SetOutputFormat(outputFormat); err != nil {
+		return err
+	}
+
+	// Arguments have been validated by this point, so any error
+	// returned from here on is not a usage mistake
+	cmd.SilenceUsage = true
+	utils.SetNonInteractive(nonInteractive)
+
+	if utils.IsStructuredOutput() || utils.IsNonInteractive() {
+		version.DisableSplash()
+	}
+
+	return nil
+}
+
+// Execute runs the root command, exiting with the code carried by the
+// returned error, see utils.ExitCode
 func Execute() {
+	rootCmd.SilenceErrors = true
+
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if utils.ShouldReportError(err) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(utils.ExitCode(err))
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", utils.OutputText, "Output format for list and status commands: text, json or yaml")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Fail instead of prompting and print plain progress logs (or set YERD_NONINTERACTIVE=1)")
+
 	phpCmd.AddCommand(php.BuildListCmd())
 	phpCmd.AddCommand(php.BuildStatusCmd())
-	phpVersions := constants.GetAvailablePhpVersions()
-	for _, version := range phpVersions {
-		phpCmd.AddCommand(php.CreateVersionCommand(version))
-	}
+	phpCmd.AddCommand(php.BuildShimsCmd())
+	phpCmd.AddCommand(php.BuildPinCmd())
+	phpCmd.AddCommand(php.BuildCacheCmd())
 
 	rootCmd.AddCommand(phpCmd)
 
@@ -55,6 +98,8 @@ func init() {
 	webCmd.AddCommand(web.BuildInstallCommand())
 	webCmd.AddCommand(web.BuildUninstallCommand())
 	webCmd.AddCommand(web.BuildTrustCommand())
+	webCmd.AddCommand(web.BuildDnsCommand())
+	webCmd.AddCommand(web.BuildCertsCommand())
 
 	rootCmd.AddCommand(webCmd)
 
@@ -62,9 +107,29 @@ func init() {
 	sitesCmd.AddCommand(sites.BuildAddCommand())
 	sitesCmd.AddCommand(sites.BuildRemoveCommand())
 	sitesCmd.AddCommand(sites.BuildSetCommand())
+	sitesCmd.AddCommand(sites.BuildParkCommand())
+	sitesCmd.AddCommand(sites.BuildUnparkCommand())
+	sitesCmd.AddCommand(sites.BuildRefreshCommand())
+	sitesCmd.AddCommand(sites.BuildSecureCommand())
+	sitesCmd.AddCommand(sites.BuildUnsecureCommand())
+	sitesCmd.AddCommand(sites.BuildProxyCommand())
+	sitesCmd.AddCommand(sites.BuildEditCommand())
+	sitesCmd.AddCommand(sites.BuildEnvCommand())
 
 	rootCmd.AddCommand(sitesCmd)
 
 	UpdateCmd.Flags().BoolVarP(&autoConfirm, "yes", "y", false, "Automatically confirm update without prompting")
 	rootCmd.AddCommand(UpdateCmd)
+	rootCmd.AddCommand(shimCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the problems which can be fixed automatically")
+	rootCmd.AddCommand(doctorCmd)
+
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Show the changes which would be made without making them")
+	rootCmd.AddCommand(applyCmd)
+
+	exportCmd.Flags().BoolVar(&exportIncludeCA, "include-ca", false, "Include the root CA key pair so imported sites stay trusted")
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(mirrorsCmd)
 }
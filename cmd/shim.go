@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lumosolutions/yerd/internal/installers/shim"
+	"github.com/spf13/cobra"
+)
+
+var shimCmd = &cobra.Command{
+	Use:                "shim <php|composer> [args...]",
+	Short:              "Runs php or composer using the PHP version pinned for the current directory",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	// Every php and composer call made through a shim runs this command,
+	// so it skips the root setup of output, mirrors and the catalogue
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := shim.Exec(args); err != nil {
+			fmt.Fprintf(os.Stderr, "yerd: %v\n", err)
+			os.Exit(1)
+		}
+
+		return nil
+	},
+}
@@ -12,19 +12,23 @@ func BuildAddCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add",
 		Short: "Adds a new local development site given a directory",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			version.PrintSplash()
 			red := color.New(color.FgRed)
 			blue := color.New(color.FgBlue)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			path := args[0]
 			domain, _ := cmd.Flags().GetString("domain")
 			folder, _ := cmd.Flags().GetString("folder")
 			php, _ := cmd.Flags().GetString("php")
+			aliases, _ := cmd.Flags().GetStringSlice("alias")
+			wildcard, _ := cmd.Flags().GetBool("wildcard")
+			siteType, _ := cmd.Flags().GetString("type")
+			pool, _ := cmd.Flags().GetBool("pool")
 
 			siteManager, err := manager.NewSiteManager()
 			if err != nil {
@@ -32,16 +36,26 @@ func BuildAddCommand() *cobra.Command {
 				red.Println("Are the web components installed?")
 				blue.Println("- You can install the web components with:")
 				blue.Println("- 'sudo yerd web install'")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
-			siteManager.AddSite(path, domain, folder, php)
+			siteManager.Aliases = aliases
+			siteManager.Wildcard = wildcard
+			siteManager.Type = siteType
+			if pool {
+				siteManager.Pool = manager.NewPoolConfig()
+			}
+			return utils.Reported(siteManager.AddSite(path, domain, folder, php))
 		},
 	}
 
 	cmd.Flags().StringP("domain", "d", "", "Override the default domain value (eg: mysite.test)")
 	cmd.Flags().StringP("folder", "f", "", "Specify a public directory under the root")
 	cmd.Flags().StringP("php", "p", "", "Specify the version of php to use")
+	cmd.Flags().StringSliceP("alias", "a", []string{}, "Additional domains served by the site (eg: api.mysite.test)")
+	cmd.Flags().BoolP("wildcard", "w", false, "Serve and secure every subdomain of the site (eg: *.mysite.test)")
+	cmd.Flags().Bool("pool", false, "Run the site in its own PHP-FPM pool with its own socket and settings")
+	cmd.Flags().StringP("type", "t", "", "Override the detected site type (laravel, symfony, wordpress, static, php)")
 
 	return cmd
 }
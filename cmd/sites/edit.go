@@ -0,0 +1,55 @@
+package sites
+
+import (
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func BuildEditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit [site]",
+		Short: "Edits custom nginx directives for a site which survive regeneration",
+		Long: `Open a site's custom nginx snippet in $EDITOR. Snippets are stored in
+/opt/yerd/web/nginx/snippets/<domain>/*.conf, are included inside the site's
+server block and are validated with 'nginx -t' before nginx is reloaded.
+
+Examples:
+  sudo -E yerd sites edit example.test
+  sudo -E yerd sites edit example.test --file uploads`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			identifier := "."
+			if len(args) > 0 {
+				identifier = args[0]
+			}
+
+			file, _ := cmd.Flags().GetString("file")
+
+			siteManager, err := manager.NewSiteManager()
+			if err != nil {
+				red.Println("Unable to create a site manager instance")
+				red.Println("Are the web components installed?")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			return utils.Reported(siteManager.EditSnippets(identifier, file))
+		},
+	}
+
+	cmd.Flags().StringP("file", "f", manager.DefaultSnippetFile, "Name of the snippet file to edit")
+
+	return cmd
+}
@@ -0,0 +1,67 @@
+package sites
+
+import (
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func BuildEnvCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "env <site> <set|unset|list> [KEY=VALUE...]",
+		Short: "Manages environment variables passed to a site's PHP",
+		Long: `Store environment variables for a site. They are passed to PHP-FPM for
+web requests and exported by the php shim when running inside the site's
+directory, variables already set in your shell take precedence.
+
+Examples:
+  sudo yerd sites env example.test set REDIS_HOST=127.0.0.1 APP_ENV=local
+  sudo yerd sites env example.test unset APP_ENV
+  yerd sites env example.test list`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			identifier := args[0]
+			action := args[1]
+			values := args[2:]
+
+			if action != "list" && !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			siteManager, err := manager.NewSiteManager()
+			if err != nil {
+				red.Println("Unable to create a site manager instance")
+				red.Println("Are the web components installed?")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			switch action {
+			case "set":
+				if len(values) == 0 {
+					red.Println("At least one KEY=VALUE is required")
+					return utils.Exit(utils.ExitUsage)
+				}
+				return utils.Reported(siteManager.SetEnv(identifier, values))
+			case "unset":
+				if len(values) == 0 {
+					red.Println("At least one KEY is required")
+					return utils.Exit(utils.ExitUsage)
+				}
+				return utils.Reported(siteManager.UnsetEnv(identifier, values))
+			case "list":
+				return utils.Reported(siteManager.ListEnv(identifier))
+			default:
+				red.Printf("Unknown action '%s', expected set, unset or list\n", action)
+				return utils.Exit(utils.ExitUsage)
+			}
+		},
+	}
+}
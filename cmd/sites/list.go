@@ -3,6 +3,7 @@ package sites
 import (
 	"github.com/fatih/color"
 	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
 	"github.com/lumosolutions/yerd/internal/version"
 	"github.com/spf13/cobra"
 )
@@ -11,7 +12,7 @@ func BuildListCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
 		Short: "Lists development sites & their configuration",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			version.PrintSplash()
 			red := color.New(color.FgRed)
 			blue := color.New(color.FgBlue)
@@ -22,10 +23,11 @@ func BuildListCommand() *cobra.Command {
 				red.Println("Are the web components installed?")
 				blue.Println("- You can install the web components with:")
 				blue.Println("- 'sudo yerd web install'")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			siteManager.ListSites()
+			return nil
 		},
 	}
 }
@@ -0,0 +1,103 @@
+package sites
+
+import (
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func BuildParkCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "park [directory]",
+		Short: "Serves every folder within a directory as <folder>.test",
+		Long: `Park a directory so that each of its subdirectories is served as a site,
+new folders are picked up automatically without running 'sites add'.
+
+Examples:
+  sudo yerd sites park ~/code
+  sudo yerd sites park .`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			siteManager, err := manager.NewSiteManager()
+			if err != nil {
+				red.Println("Unable to create a site manager instance")
+				red.Println("Are the web components installed?")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			return utils.Reported(siteManager.Park(path))
+		},
+	}
+}
+
+func BuildUnparkCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unpark [directory]",
+		Short: "Stops serving the folders within a parked directory",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			siteManager, err := manager.NewSiteManager()
+			if err != nil {
+				red.Println("Unable to create a site manager instance")
+				red.Println("Are the web components installed?")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			return utils.Reported(siteManager.Unpark(path))
+		},
+	}
+}
+
+func BuildRefreshCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh",
+		Short: "Registers new folders found in parked directories",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			if err := manager.RefreshParked(); err != nil {
+				red.Printf("Unable to refresh parked directories: %v\n", err)
+				return utils.Exit(utils.ExitFailure)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package sites
+
+import (
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func BuildProxyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proxy <domain> <upstream>",
+		Short: "Adds a site which forwards requests to another local server",
+		Long: `Serve a domain over trusted HTTPS and forward every request, including
+websocket upgrades for hot module reloading, to an upstream server.
+
+Examples:
+  sudo yerd sites proxy vite.test http://127.0.0.1:5173
+  sudo yerd sites proxy api.test 127.0.0.1:8080 --alias api.myapp.test`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			aliases, _ := cmd.Flags().GetStringSlice("alias")
+			wildcard, _ := cmd.Flags().GetBool("wildcard")
+
+			siteManager, err := manager.NewSiteManager()
+			if err != nil {
+				red.Println("Unable to create a site manager instance")
+				red.Println("Are the web components installed?")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			siteManager.Aliases = aliases
+			siteManager.Wildcard = wildcard
+			return utils.Reported(siteManager.AddProxy(args[0], args[1]))
+		},
+	}
+
+	cmd.Flags().StringSliceP("alias", "a", []string{}, "Additional domains served by the proxy (eg: api.mysite.test)")
+	cmd.Flags().BoolP("wildcard", "w", false, "Serve and secure every subdomain of the proxy (eg: *.mysite.test)")
+
+	return cmd
+}
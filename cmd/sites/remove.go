@@ -12,13 +12,13 @@ func BuildRemoveCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "remove",
 		Short: "Removes a local development site given a directory",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			version.PrintSplash()
 			red := color.New(color.FgRed)
 			blue := color.New(color.FgBlue)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			path := args[0]
@@ -29,10 +29,10 @@ func BuildRemoveCommand() *cobra.Command {
 				red.Println("Are the web components installed?")
 				blue.Println("- You can install the web components with:")
 				blue.Println("- 'sudo yerd web install'")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
-			siteManager.RemoveSite(path)
+			return utils.Reported(siteManager.RemoveSite(path))
 		},
 	}
 }
@@ -0,0 +1,87 @@
+package sites
+
+import (
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func BuildSecureCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "secure [site]",
+		Short: "Serves a site over HTTPS using a certificate signed by the YERD CA",
+		Long: `Issue a certificate for a site and serve it over HTTPS, the site can be
+identified by its domain or directory.
+
+Examples:
+  sudo yerd sites secure example.test
+  sudo yerd sites secure .`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			identifier := "."
+			if len(args) > 0 {
+				identifier = args[0]
+			}
+
+			siteManager, err := manager.NewSiteManager()
+			if err != nil {
+				red.Println("Unable to create a site manager instance")
+				red.Println("Are the web components installed?")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			return utils.Reported(siteManager.Secure(identifier))
+		},
+	}
+}
+
+func BuildUnsecureCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unsecure [site]",
+		Short: "Serves a site over plain HTTP and removes its certificate",
+		Long: `Serve a site over plain HTTP, the site can be identified by its domain
+or directory.
+
+Examples:
+  sudo yerd sites unsecure example.test
+  sudo yerd sites unsecure .`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			identifier := "."
+			if len(args) > 0 {
+				identifier = args[0]
+			}
+
+			siteManager, err := manager.NewSiteManager()
+			if err != nil {
+				red.Println("Unable to create a site manager instance")
+				red.Println("Are the web components installed?")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			return utils.Reported(siteManager.Unsecure(identifier))
+		},
+	}
+}
@@ -12,7 +12,7 @@ func BuildSetCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "set",
 		Short: "Sets a configuration value for a given site",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			version.PrintSplash()
 			//green := color.New(color.FgGreen)
 			//yellow := color.New(color.FgYellow)
@@ -20,7 +20,7 @@ func BuildSetCommand() *cobra.Command {
 			red := color.New(color.FgRed)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			if len(args) < 3 {
@@ -28,7 +28,17 @@ func BuildSetCommand() *cobra.Command {
 				blue.Println("- yerd sites set <name> <value> <site>")
 				blue.Println("- Examples:")
 				blue.Println("- 'sudo yerd sites set php 8.3 example.test'")
-				return
+				blue.Println("- 'sudo yerd sites set proxy http://127.0.0.1:3000 vite.test'")
+				blue.Println("- 'sudo yerd sites set type wordpress blog.test'")
+				blue.Println("- 'sudo yerd sites set domain shop.test example.test'")
+				blue.Println("- 'sudo yerd sites set public web example.test'")
+				blue.Println("- 'sudo yerd sites set directory ~/code/example example.test'")
+				blue.Println("- 'sudo yerd sites set secure off example.test'")
+				blue.Println("- 'sudo yerd sites set aliases api.example.test,admin.example.test example.test'")
+				blue.Println("- 'sudo yerd sites set pool on example.test'")
+				blue.Println("- 'sudo yerd sites set pool.max_children 10 example.test'")
+				blue.Println("- 'sudo yerd sites set ini.memory_limit 2G example.test'")
+				return utils.Exit(utils.ExitUsage)
 			}
 
 			setName := args[0]
@@ -41,10 +51,10 @@ func BuildSetCommand() *cobra.Command {
 				red.Println("Are the web components installed?")
 				blue.Println("- You can install the web components with:")
 				blue.Println("- 'sudo yerd web install'")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
-			siteManager.SetValue(setName, setValue, siteIdentifier)
+			return utils.Reported(siteManager.SetValue(setName, setValue, siteIdentifier))
 		},
 	}
 }
@@ -42,13 +42,13 @@ Examples:
   yerd update           # Check for and install updates
   yerd update -y        # Auto-confirm update without prompting`,
 	Args: cobra.NoArgs,
-	Run:  runUpdate,
+	RunE: runUpdate,
 }
 
 var autoConfirm bool
 
 // runUpdate executes the YERD self-update process by checking for new releases and installing them.
-func runUpdate(cmd *cobra.Command, args []string) {
+func runUpdate(cmd *cobra.Command, args []string) error {
 	version.PrintSplash()
 
 	currentVersion := version.GetVersion()
@@ -63,40 +63,47 @@ func runUpdate(cmd *cobra.Command, args []string) {
 	if err != nil {
 		fmt.Printf("❌ Failed to check for updates: %v\n", err)
 		fmt.Printf("💡 Check your internet connection and try again\n")
-		return
+		return utils.Exit(utils.ExitFailure)
 	}
 
 	latestVersion := strings.TrimPrefix(latestRelease.TagName, "v")
 	if currentVersion == latestVersion {
 		fmt.Printf("✅ YERD is already up to date (v%s)\n", currentVersion)
-		return
+		return nil
 	}
 
 	if currentVersion != "unknown" && !isNewerVersion(latestVersion, currentVersion) {
 		fmt.Printf("ℹ️  You have a newer or development version (v%s) than the latest release (v%s)\n", currentVersion, latestVersion)
 		fmt.Printf("💡 No update needed\n")
-		return
+		return nil
 	}
 
 	fmt.Printf("🆕 New version available: v%s\n", latestVersion)
 
 	if !utils.CheckAndPromptForSudo() {
-		return
+		return utils.Exit(utils.ExitPermission)
 	}
 
-	if !confirmUpdate(latestVersion) {
+	confirmed, err := confirmUpdate(latestVersion)
+	if err != nil {
+		fmt.Printf("❌ Update cancelled, use --yes to update non-interactively\n")
+		return utils.ExitWithError(utils.ExitInteractionRequired, err)
+	}
+
+	if !confirmed {
 		fmt.Printf("❌ Update cancelled\n")
-		return
+		return nil
 	}
 
 	if err := performUpdate(latestRelease); err != nil {
 		fmt.Printf("❌ Update failed: %v\n", err)
 		fmt.Printf("💡 You can manually download from: https://github.com/LumoSolutions/yerd/releases\n")
-		return
+		return utils.Exit(utils.ExitFailure)
 	}
 
 	fmt.Printf("✅ YERD updated successfully to v%s\n", latestVersion)
 	fmt.Printf("💡 Run 'yerd --version' to verify the update\n")
+	return nil
 }
 
 // fetchLatestRelease retrieves the latest YERD release information from GitHub API.
@@ -154,19 +161,15 @@ func isNewerVersion(latest, current string) bool {
 }
 
 // confirmUpdate prompts user for update confirmation or auto-confirms based on flags.
-// version: Version string to update to. Returns true if user confirms or auto-confirm is enabled.
-func confirmUpdate(version string) bool {
+// version: Version string to update to. Returns true if user confirms or auto-confirm is enabled,
+// or utils.ErrInteractionRequired when a prompt is needed in non-interactive mode.
+func confirmUpdate(version string) (bool, error) {
 	if autoConfirm {
 		fmt.Printf("🔄 Auto-updating to v%s...\n", version)
-		return true
+		return true, nil
 	}
 
-	fmt.Printf("🔄 Update to v%s? (y/N): ", version)
-	var response string
-	fmt.Scanln(&response)
-	response = strings.ToLower(strings.TrimSpace(response))
-
-	return response == "y" || response == "yes"
+	return utils.Confirm(fmt.Sprintf("🔄 Update to v%s?", version))
 }
 
 // performUpdate downloads and installs the new YERD version from GitHub release.
@@ -0,0 +1,129 @@
+package web
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+const certExpiryWarningDays = 30
+
+func BuildCertsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "certs",
+		Short: "Lists the YERD CA and site certificates with their expiry",
+		Long: `List every certificate managed by YERD, including the root CA, showing
+the subject, the names it covers and when it expires.
+
+Examples:
+  yerd web certs
+  sudo yerd web certs renew
+  sudo yerd web certs renew example.test`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			if !config.GetWebConfig().Installed {
+				red.Println("YERD web components are not installed")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			certificates, err := manager.ListCertificates()
+			if err != nil {
+				red.Println("Unable to read certificates")
+				blue.Printf("- %v\n", err)
+				return utils.Exit(utils.ExitFailure)
+			}
+
+			if len(certificates) == 0 {
+				blue.Println("No certificates found")
+				return nil
+			}
+
+			for _, certificate := range certificates {
+				outputCertificate(certificate)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(buildCertsRenewCommand())
+
+	return cmd
+}
+
+func buildCertsRenewCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "renew [site...]",
+		Short: "Reissues site certificates and reloads nginx",
+		Long: `Reissue the certificates for the given sites, or every secure site when
+none are given, then reload nginx.
+
+Examples:
+  sudo yerd web certs renew
+  sudo yerd web certs renew example.test api.test`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			red := color.New(color.FgRed)
+			blue := color.New(color.FgBlue)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			siteManager, err := manager.NewSiteManager()
+			if err != nil {
+				red.Println("Unable to create a site manager instance")
+				red.Println("Are the web components installed?")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			return utils.Reported(siteManager.RenewCertificates(args))
+		},
+	}
+}
+
+func outputCertificate(certificate *manager.CertificateInfo) {
+	green := color.New(color.FgGreen)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed)
+
+	name := strings.TrimSuffix(filepath.Base(certificate.Path), ".crt")
+	if certificate.IsCA {
+		fmt.Printf("🔒 Root CA: %s\n", name)
+	} else {
+		fmt.Printf("🌐 Site: %s\n", name)
+	}
+
+	fmt.Printf("├─ Subject: %s\n", certificate.Subject)
+	if len(certificate.Names) > 0 {
+		fmt.Printf("├─ Names: %s\n", strings.Join(certificate.Names, ", "))
+	}
+	fmt.Printf("├─ Path: %s\n", certificate.Path)
+	fmt.Printf("└─ Expires: %s ", certificate.NotAfter.Local().Format("2006-01-02"))
+
+	days := certificate.DaysRemaining()
+	switch {
+	case certificate.Expired():
+		red.Println("(expired)")
+	case days <= certExpiryWarningDays:
+		yellow.Printf("(%d days remaining)\n", days)
+	default:
+		green.Printf("(%d days remaining)\n", days)
+	}
+
+	fmt.Println()
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/dns"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func BuildDnsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dns",
+		Short: "Manage the local DNS resolver for *.test domains",
+		Long: `Run a small DNS responder which answers every *.test name, including
+wildcard subdomains such as tenant1.app.test, with 127.0.0.1.
+
+Examples:
+  sudo yerd web dns install
+  sudo yerd web dns uninstall
+  yerd web dns status`,
+	}
+
+	cmd.AddCommand(buildDnsInstallCommand())
+	cmd.AddCommand(buildDnsUninstallCommand())
+	cmd.AddCommand(buildDnsStatusCommand())
+	cmd.AddCommand(buildDnsServeCommand())
+
+	return cmd
+}
+
+func buildDnsInstallCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install",
+		Short: "Installs the yerd-dns service and configures split DNS",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			green := color.New(color.FgGreen)
+			blue := color.New(color.FgBlue)
+			red := color.New(color.FgRed)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			if !config.GetWebConfig().Installed {
+				red.Println("YERD web components are not installed")
+				blue.Println("- You can install the web components with:")
+				blue.Println("- 'sudo yerd web install'")
+				return utils.Exit(utils.ExitNotInstalled)
+			}
+
+			resolver, err := dns.Install()
+			if err != nil {
+				red.Println("❌ Error: Unable to install the DNS resolver")
+				blue.Printf("- %v\n\n", err)
+				return utils.Exit(utils.ExitFailure)
+			}
+
+			green.Println("✓ DNS resolver installed")
+			blue.Printf("- *.%s now resolves to 127.0.0.1\n", constants.DNSDomain)
+			blue.Printf("- Listening on %s via %s\n", constants.DNSListenAddress, resolver)
+			return nil
+		},
+	}
+}
+
+func buildDnsUninstallCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Removes the yerd-dns service and split DNS configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+			green := color.New(color.FgGreen)
+			red := color.New(color.FgRed)
+
+			if !utils.CheckAndPromptForSudo() {
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			if err := dns.Uninstall(); err != nil {
+				red.Printf("Unable to uninstall the DNS resolver: %v\n", err)
+				return utils.Exit(utils.ExitFailure)
+			}
+
+			green.Println("✓ DNS resolver uninstalled")
+			return nil
+		},
+	}
+}
+
+func buildDnsStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Shows the state of the local DNS resolver",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version.PrintSplash()
+
+			status := "Stopped"
+			if dns.IsRunning() {
+				status = "Running"
+			}
+
+			resolver, err := dns.DetectResolver()
+			if err != nil {
+				resolver = "Unsupported"
+			}
+
+			fmt.Printf("🧭 YERD DNS\n")
+			fmt.Printf("├─ Enabled: %t\n", config.GetWebConfig().DNS)
+			fmt.Printf("├─ Domain: *.%s\n", constants.DNSDomain)
+			fmt.Printf("├─ Listen: %s\n", constants.DNSListenAddress)
+			fmt.Printf("├─ Resolver: %s\n", resolver)
+			fmt.Printf("└─ Service: %s\n\n", status)
+			return nil
+		},
+	}
+}
+
+func buildDnsServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:    "serve",
+		Short:  "Runs the DNS responder in the foreground",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server := dns.NewServer(constants.DNSListenAddress, constants.DNSDomain)
+			if err := server.ListenAndServe(); err != nil {
+				fmt.Fprintf(os.Stderr, "yerd-dns: %v\n", err)
+				os.Exit(1)
+			}
+
+			return nil
+		},
+	}
+}
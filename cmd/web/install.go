@@ -2,17 +2,22 @@ package web
 
 import (
 	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/config"
 	"github.com/lumosolutions/yerd/internal/installers/nginx"
+	"github.com/lumosolutions/yerd/internal/manager"
 	"github.com/lumosolutions/yerd/internal/utils"
 	"github.com/lumosolutions/yerd/internal/version"
 	"github.com/spf13/cobra"
 )
 
 func BuildInstallCommand() *cobra.Command {
-	return &cobra.Command{
+	var keyType string
+	var certDays int
+
+	cmd := &cobra.Command{
 		Use:   "install",
 		Short: "Installs any web components required for local development sites",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			version.PrintSplash()
 			//green := color.New(color.FgGreen)
 			//yellow := color.New(color.FgYellow)
@@ -20,15 +25,33 @@ func BuildInstallCommand() *cobra.Command {
 			red := color.New(color.FgRed)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
+			}
+
+			if keyType != manager.KeyTypeRSA && keyType != manager.KeyTypeECDSA {
+				red.Printf("Unsupported key type '%s', expected rsa or ecdsa\n", keyType)
+				return utils.Exit(utils.ExitUsage)
 			}
 
+			certConfig := config.GetWebConfig().Certs
+			certConfig.KeyType = keyType
+			if certDays > 0 {
+				certConfig.ValidityDays = certDays
+			}
+			config.SetStruct("web.certs", certConfig)
+
 			installer, err := nginx.NewNginxInstaller(false, true)
 			if err != nil {
 				red.Printf("Install failed\n\n")
+				return utils.Exit(utils.ExitFailure)
 			}
 
-			installer.Install()
+			return utils.Reported(installer.Install())
 		},
 	}
+
+	cmd.Flags().StringVar(&keyType, "key-type", manager.KeyTypeRSA, "Key type for generated certificates, rsa or ecdsa")
+	cmd.Flags().IntVar(&certDays, "cert-days", 0, "Number of days site certificates are valid for (default 3650)")
+
+	return cmd
 }
@@ -16,20 +16,20 @@ func BuildTrustCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "trust",
 		Short: "Attempts to refresh the YERD CA for Chrome",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			version.PrintSplash()
 			red := color.New(color.FgRed)
 			green := color.New(color.FgGreen)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			webConfig := config.GetWebConfig()
 
 			if !webConfig.Installed {
 				red.Println("YERD web components are not installed")
-				return
+				return utils.Exit(utils.ExitNotInstalled)
 			}
 
 			cm := manager.NewCertificateManager()
@@ -41,10 +41,11 @@ func BuildTrustCommand() *cobra.Command {
 			if err := cm.ChromeTrust(caPath, caFile); err != nil {
 				red.Println("Unable to trust CA cert with chrome due to the following error:")
 				red.Println(err)
-				return
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			green.Println("Chrome Trust Updated")
+			return nil
 		},
 	}
 }
@@ -13,18 +13,19 @@ func BuildUninstallCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "uninstall",
 		Short: "Uninstalls the web components required for local development",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			version.PrintSplash()
 			green := color.New(color.FgGreen)
 			red := color.New(color.FgRed)
 
 			if !utils.CheckAndPromptForSudo() {
-				return
+				return utils.Exit(utils.ExitPermission)
 			}
 
 			installer, err := nginx.NewNginxInstaller(false, true)
 			if err != nil {
 				red.Printf("Install failed\n\n")
+				return utils.Exit(utils.ExitFailure)
 			}
 
 			installer.Uninstall()
@@ -36,6 +37,7 @@ func BuildUninstallCommand() *cobra.Command {
 			config.SetStruct("web", newConfig)
 
 			green.Println("Successfully uninstalled web components")
+			return nil
 		},
 	}
 }
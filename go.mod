@@ -2,7 +2,10 @@ module github.com/lumosolutions/yerd
 
 go 1.24.6
 
-require github.com/fatih/color v1.18.0
+require (
+	github.com/fatih/color v1.18.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -18,5 +21,5 @@ require (
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/olekukonko/tablewriter v1.0.9
 	github.com/spf13/cobra v1.9.1
-	golang.org/x/sys v0.25.0 // indirect
+	golang.org/x/sys v0.25.0
 )
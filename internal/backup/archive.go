@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Names of the entries within an export archive, directories are
+// stored under a prefix matching their purpose
+const (
+	metadataEntry = "yerd-export.json"
+	configEntry   = "config.json"
+	etcPrefix     = "etc/"
+	snippetPrefix = "snippets/"
+	caPrefix      = "ca/"
+)
+
+// archiveWriter writes files into a gzip compressed tarball
+type archiveWriter struct {
+	file *os.File
+	gzip *gzip.Writer
+	tar  *tar.Writer
+}
+
+func newArchiveWriter(path string) (*archiveWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(file)
+	return &archiveWriter{file: file, gzip: gz, tar: tar.NewWriter(gz)}, nil
+}
+
+// add writes content to the archive as name
+func (w *archiveWriter) add(name string, content []byte, mode fs.FileMode) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    int64(mode.Perm()),
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+
+	if err := w.tar.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err := w.tar.Write(content)
+	return err
+}
+
+// addDirectory adds every regular file below dir, named relative to it
+// under prefix, a missing directory adds nothing
+func (w *archiveWriter) addDirectory(dir, prefix string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+
+		relative, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		return w.add(prefix+filepath.ToSlash(relative), content, info.Mode())
+	})
+}
+
+func (w *archiveWriter) close() error {
+	tarErr := w.tar.Close()
+	gzipErr := w.gzip.Close()
+	fileErr := w.file.Close()
+
+	for _, err := range []error{tarErr, gzipErr, fileErr} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// archiveFile is a single regular file read from an archive
+type archiveFile struct {
+	content []byte
+	mode    fs.FileMode
+}
+
+// readArchive reads every regular file in the tarball at archivePath,
+// rejecting entry names which would escape the directory they are
+// restored into
+func readArchive(archivePath string) (map[string]archiveFile, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("not a yerd export: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string]archiveFile)
+	reader := tar.NewReader(gz)
+
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not a yerd export: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("archive entry %s is outside the archive", header.Name)
+		}
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		files[name] = archiveFile{content: content, mode: fs.FileMode(header.Mode).Perm()}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,101 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"github.com/lumosolutions/yerd/internal/version"
+)
+
+// Metadata describes an export archive and what it contains
+type Metadata struct {
+	YerdVersion string    `json:"yerd_version"`
+	Created     time.Time `json:"created"`
+	Composer    bool      `json:"composer"`
+	IncludesCA  bool      `json:"includes_ca"`
+}
+
+// Export writes the YERD configuration, the configuration of every PHP
+// version, custom nginx snippets and optionally the root CA key pair to
+// a gzip compressed tarball at path
+// path: The archive to create, includeCA: Include the CA private key
+func Export(path string, includeCA bool) error {
+	writer, err := newArchiveWriter(path)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", path, err)
+	}
+
+	if err := writeExport(writer, includeCA); err != nil {
+		writer.close()
+		os.Remove(path)
+		return err
+	}
+
+	if err := writer.close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if userCtx, err := utils.GetRealUser(); err == nil {
+		utils.Chown(path, userCtx.UID, userCtx.GID)
+	}
+
+	return nil
+}
+
+func writeExport(writer *archiveWriter, includeCA bool) error {
+	data, err := config.GetAll()
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode configuration: %w", err)
+	}
+
+	if err := writer.add(configEntry, content, constants.FilePermissions); err != nil {
+		return err
+	}
+
+	for _, version := range constants.GetAvailablePhpVersions() {
+		if _, installed := config.GetInstalledPhpInfo(version); !installed {
+			continue
+		}
+
+		dir := "php" + version
+		if err := writer.addDirectory(filepath.Join(constants.YerdEtcDir, dir), etcPrefix+dir+"/"); err != nil {
+			return fmt.Errorf("unable to export PHP %s configuration: %w", version, err)
+		}
+	}
+
+	if err := writer.addDirectory(constants.NginxSnippetsDir, snippetPrefix); err != nil {
+		return fmt.Errorf("unable to export nginx snippets: %w", err)
+	}
+
+	if includeCA {
+		if err := writer.addDirectory(filepath.Join(constants.CertsDir, "ca"), caPrefix); err != nil {
+			return fmt.Errorf("unable to export the certificate authority: %w", err)
+		}
+	}
+
+	metadata := Metadata{
+		YerdVersion: version.Version,
+		Created:     time.Now(),
+		Composer:    utils.FileExists(constants.LocalComposerPath),
+		IncludesCA:  includeCA,
+	}
+
+	content, err = json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writer.add(metadataEntry, content, constants.FilePermissions)
+}
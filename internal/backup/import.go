@@ -0,0 +1,397 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/dns"
+	"github.com/lumosolutions/yerd/internal/installers/composer"
+	"github.com/lumosolutions/yerd/internal/installers/nginx"
+	phpinstaller "github.com/lumosolutions/yerd/internal/installers/php"
+	"github.com/lumosolutions/yerd/internal/installers/shim"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// exportedConfig is the part of an exported config.json which import
+// rebuilds, everything else is recreated by the installers
+type exportedConfig struct {
+	Php   config.PhpConfig   `json:"php"`
+	Web   *config.WebConfig  `json:"web"`
+	Shims *config.ShimConfig `json:"shims"`
+}
+
+// Importer restores an export archive onto a machine without any YERD
+// managed PHP versions or web components, or resumes an earlier import
+// of the same archive which failed part way through
+type Importer struct {
+	files    map[string]archiveFile
+	metadata Metadata
+	config   exportedConfig
+	resumed  map[string]bool
+	warnings []string
+	err      error
+}
+
+// NewImporter reads and validates the export archive at path
+func NewImporter(path string) (*Importer, error) {
+	files, err := readArchive(path)
+	if err != nil {
+		return nil, err
+	}
+
+	importer := &Importer{files: files, resumed: map[string]bool{}}
+
+	metadata, found := files[metadataEntry]
+	if !found {
+		return nil, fmt.Errorf("not a yerd export: %s is missing", metadataEntry)
+	}
+
+	if err := json.Unmarshal(metadata.content, &importer.metadata); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", metadataEntry, err)
+	}
+
+	exported, found := files[configEntry]
+	if !found {
+		return nil, fmt.Errorf("not a yerd export: %s is missing", configEntry)
+	}
+
+	if err := json.Unmarshal(exported.content, &importer.config); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", configEntry, err)
+	}
+
+	return importer, nil
+}
+
+// Metadata returns the description of the archive being imported
+func (importer *Importer) Metadata() Metadata {
+	return importer.metadata
+}
+
+// PhpVersions returns the PHP versions in the archive, oldest first
+func (importer *Importer) PhpVersions() []string {
+	versions := []string{}
+	for _, version := range constants.GetAvailablePhpVersions() {
+		if _, found := importer.config.Php[version]; found {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions
+}
+
+// Sites returns the domains of the sites in the archive, sorted
+func (importer *Importer) Sites() []string {
+	domains := []string{}
+	if importer.config.Web != nil {
+		for domain := range importer.config.Web.Sites {
+			domains = append(domains, domain)
+		}
+	}
+
+	sort.Strings(domains)
+	return domains
+}
+
+// Warnings returns the parts of the archive which could not be restored
+// without stopping the import, such as a site whose directory is missing
+func (importer *Importer) Warnings() []string {
+	return importer.warnings
+}
+
+// Import rebuilds every PHP version at its recorded version, restores
+// its configuration, then installs composer and the web components and
+// recreates the sites, parked directories, DNS resolver and shims
+func (importer *Importer) Import() error {
+	if err := importer.checkFreshInstall(); err != nil {
+		return err
+	}
+
+	importer.
+		run(importer.restoreEtc).
+		run(importer.installPhp).
+		run(importer.installComposer).
+		run(importer.installWeb).
+		run(importer.restoreSnippets).
+		run(importer.restoreSites).
+		run(importer.restoreParked).
+		run(importer.restoreDns).
+		run(importer.restoreShims)
+
+	return importer.err
+}
+
+func (importer *Importer) run(fn func() error) *Importer {
+	if importer.err == nil {
+		importer.err = fn()
+	}
+
+	return importer
+}
+
+func (importer *Importer) warn(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	importer.warnings = append(importer.warnings, message)
+	color.New(color.FgYellow).Printf("⚠ %s\n", message)
+}
+
+// checkFreshInstall refuses to import over an existing environment, as
+// installed versions and sites would clash with those in the archive. A
+// version already installed at its recorded version, or web components
+// installed when the archive has them and no other PHP is installed,
+// were left by an earlier import of this archive, so the import resumes
+func (importer *Importer) checkFreshInstall() error {
+	for _, version := range constants.GetAvailablePhpVersions() {
+		installed, found := config.GetInstalledPhpInfo(version)
+		if !found {
+			continue
+		}
+
+		recorded, exported := importer.config.Php[version]
+		if !exported || recorded.InstalledVersion == "" || recorded.InstalledVersion != installed.InstalledVersion {
+			return fmt.Errorf("PHP %s is already installed, import requires a fresh installation", version)
+		}
+
+		importer.resumed[version] = true
+	}
+
+	exportedWeb := importer.config.Web != nil && importer.config.Web.Installed
+	if config.GetWebConfig().Installed && !exportedWeb {
+		return fmt.Errorf("web components are already installed, import requires a fresh installation")
+	}
+
+	return nil
+}
+
+// restoreEtc writes the exported php.ini, FPM and pool configuration
+// before PHP is built, the installers leave existing configuration alone
+func (importer *Importer) restoreEtc() error {
+	return importer.restoreFiles(etcPrefix, constants.YerdEtcDir)
+}
+
+func (importer *Importer) restoreSnippets() error {
+	return importer.restoreFiles(snippetPrefix, constants.NginxSnippetsDir)
+}
+
+// restoreFiles writes every archive entry under prefix below dir
+func (importer *Importer) restoreFiles(prefix, dir string) error {
+	for name, file := range importer.files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, prefix)))
+		if err := utils.WriteToFile(target, file.content, file.mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (importer *Importer) installPhp() error {
+	cli := ""
+
+	for _, version := range importer.PhpVersions() {
+		info := importer.config.Php[version]
+
+		if info.IsCLI {
+			cli = version
+		}
+
+		if importer.resumed[version] {
+			color.New(color.FgGreen).Printf("✓ PHP %s is already installed at %s\n", version, info.InstalledVersion)
+			continue
+		}
+
+		installer, err := phpinstaller.NewPhpInstaller(version, false, false)
+		if err != nil {
+			return err
+		}
+
+		if info.InstalledVersion != "" {
+			installer.UseVersion(info.InstalledVersion)
+		}
+		installer.UseExtensions(info.Extensions)
+
+		if err := installer.Install(); err != nil {
+			return fmt.Errorf("unable to build PHP %s: %w", version, err)
+		}
+		fmt.Println()
+
+		if len(info.AddExtensions) > 0 || len(info.RemoveExtensions) > 0 {
+			installed, _ := config.GetInstalledPhpInfo(version)
+			installed.AddExtensions = info.AddExtensions
+			installed.RemoveExtensions = info.RemoveExtensions
+			config.SetStruct(fmt.Sprintf("php.[%s]", version), installed)
+		}
+	}
+
+	if cli == "" {
+		return nil
+	}
+
+	info, _ := config.GetInstalledPhpInfo(cli)
+	if err := phpinstaller.SetCliVersion(info); err != nil {
+		importer.warn("Unable to set PHP %s as the default CLI version", cli)
+	}
+
+	return nil
+}
+
+func (importer *Importer) installComposer() error {
+	if !importer.metadata.Composer || utils.FileExists(constants.LocalComposerPath) {
+		return nil
+	}
+
+	if err := composer.InstallComposer(); err != nil {
+		return fmt.Errorf("unable to install composer: %w", err)
+	}
+
+	color.New(color.FgGreen).Println("✓ Composer installed")
+	return nil
+}
+
+// installWeb installs nginx with the exported certificate settings and,
+// when the archive includes it, swaps in the exported root CA so that
+// browsers which already trust it keep doing so
+func (importer *Importer) installWeb() error {
+	web := importer.config.Web
+	if web == nil || !web.Installed {
+		return nil
+	}
+
+	if config.GetWebConfig().Installed {
+		color.New(color.FgGreen).Println("✓ Web components are already installed")
+		return nil
+	}
+
+	config.SetStruct("web.certs", web.Certs)
+
+	installer, err := nginx.NewNginxInstaller(false, true)
+	if err != nil {
+		return err
+	}
+
+	if err := installer.Install(); err != nil {
+		return fmt.Errorf("unable to install the web components: %w", err)
+	}
+	fmt.Println()
+
+	key, hasKey := importer.files[caPrefix+"yerd.key"]
+	cert, hasCert := importer.files[caPrefix+"yerd.crt"]
+	if !hasKey || !hasCert {
+		return nil
+	}
+
+	if err := manager.NewCertificateManager().RestoreCaCertificate("yerd", key.content, cert.content); err != nil {
+		importer.warn("Unable to restore the exported certificate authority: %v", err)
+	}
+
+	return nil
+}
+
+// restoreSites recreates every site with its exported settings, parked
+// sites included so their customisations are kept
+func (importer *Importer) restoreSites() error {
+	web := importer.config.Web
+	if web == nil || !web.Installed {
+		return nil
+	}
+
+	existing := config.GetWebConfig().Sites
+
+	for _, domain := range importer.Sites() {
+		site := web.Sites[domain]
+		if _, found := existing[domain]; found {
+			continue
+		}
+
+		siteManager, err := manager.NewSiteManager()
+		if err != nil {
+			return err
+		}
+
+		siteManager.Aliases = site.Aliases
+		siteManager.Wildcard = site.Wildcard
+		siteManager.Insecure = site.Insecure
+		siteManager.Type = site.Type
+		siteManager.Pool = site.Pool
+		siteManager.Env = site.Env
+		siteManager.ParkedIn = site.ParkedIn
+
+		if site.Proxy != "" {
+			err = siteManager.AddProxy(site.Domain, site.Proxy)
+		} else {
+			err = siteManager.AddSite(site.RootDirectory, site.Domain, site.PublicDirectory, site.PhpVersion)
+		}
+
+		if err != nil {
+			importer.warn("Unable to restore %s: %v", site.Domain, err)
+		}
+	}
+
+	return nil
+}
+
+// restoreParked parks each exported directory again, which also adds
+// any folder created there since the export
+func (importer *Importer) restoreParked() error {
+	web := importer.config.Web
+	if web == nil || !web.Installed {
+		return nil
+	}
+
+	for _, parked := range web.Parked {
+		if !utils.IsDirectory(parked) {
+			importer.warn("Unable to park %s, the directory does not exist", parked)
+			continue
+		}
+
+		siteManager, err := manager.NewSiteManager()
+		if err != nil {
+			return err
+		}
+
+		if err := siteManager.Park(parked); err != nil {
+			importer.warn("Unable to park %s: %v", parked, err)
+		}
+	}
+
+	return nil
+}
+
+func (importer *Importer) restoreDns() error {
+	web := importer.config.Web
+	if web == nil || !web.Installed || !web.DNS {
+		return nil
+	}
+
+	if _, err := dns.Install(); err != nil {
+		importer.warn("Unable to install the DNS resolver: %v", err)
+		return nil
+	}
+
+	color.New(color.FgGreen).Println("✓ DNS resolver installed")
+	return nil
+}
+
+func (importer *Importer) restoreShims() error {
+	if importer.config.Shims == nil || !importer.config.Shims.Enabled {
+		return nil
+	}
+
+	if err := shim.Enable(); err != nil {
+		importer.warn("Unable to enable the php and composer shims: %v", err)
+		return nil
+	}
+
+	color.New(color.FgGreen).Println("✓ Shims enabled")
+	return nil
+}
@@ -0,0 +1,22 @@
+package config
+
+// BuildCacheConfig controls the cache of compiled PHP builds, Mirror is
+// an optional directory or HTTP(S) URL shared by a team and Digests are
+// the SHA-256 of every build compiled on this machine, keyed by name
+type BuildCacheConfig struct {
+	Disabled bool              `json:"disabled"`
+	Mirror   string            `json:"mirror,omitempty"`
+	Digests  map[string]string `json:"digests,omitempty"`
+}
+
+// GetBuildCacheConfig returns the build cache configuration, the cache
+// is enabled without a mirror unless configured otherwise
+func GetBuildCacheConfig() *BuildCacheConfig {
+	var cacheConfig *BuildCacheConfig
+	err := GetStruct("build_cache", &cacheConfig)
+	if err != nil || cacheConfig == nil {
+		cacheConfig = &BuildCacheConfig{}
+	}
+
+	return cacheConfig
+}
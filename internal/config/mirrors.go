@@ -0,0 +1,14 @@
+package config
+
+import "github.com/lumosolutions/yerd/internal/utils"
+
+// GetMirrors returns the configured source mirrors, every source is
+// downloaded from its default location unless configured otherwise
+func GetMirrors() utils.Mirrors {
+	var mirrors utils.Mirrors
+	if err := GetStruct("mirrors", &mirrors); err != nil {
+		return utils.Mirrors{}
+	}
+
+	return mirrors
+}
@@ -0,0 +1,20 @@
+package config
+
+type ShimConfig struct {
+	Enabled bool   `json:"enabled"`
+	Binary  string `json:"binary"`
+}
+
+// GetShimConfig returns the shim configuration, if shims have
+// never been configured then a disabled configuration is returned
+func GetShimConfig() *ShimConfig {
+	var shimConfig *ShimConfig
+	err := GetStruct("shims", &shimConfig)
+	if err != nil || shimConfig == nil {
+		shimConfig = &ShimConfig{
+			Enabled: false,
+		}
+	}
+
+	return shimConfig
+}
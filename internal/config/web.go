@@ -3,13 +3,39 @@ package config
 type WebConfig struct {
 	Installed bool                  `json:"is_installed"`
 	Sites     map[string]SiteConfig `json:"sites"`
+	Parked    []string              `json:"parked"`
+	DNS       bool                  `json:"dns"`
+	Certs     CertConfig            `json:"certs"`
+}
+
+type CertConfig struct {
+	KeyType        string `json:"key_type"`
+	ValidityDays   int    `json:"validity_days"`
+	CaValidityDays int    `json:"ca_validity_days"`
 }
 
 type SiteConfig struct {
-	RootDirectory   string `json:"rootDir"`
-	PublicDirectory string `json:"publicDir"`
-	Domain          string `json:"domain"`
-	PhpVersion      string `json:"php_version"`
+	RootDirectory   string            `json:"rootDir"`
+	PublicDirectory string            `json:"publicDir"`
+	Domain          string            `json:"domain"`
+	PhpVersion      string            `json:"php_version"`
+	ParkedIn        string            `json:"parked_in,omitempty"`
+	Aliases         []string          `json:"aliases,omitempty"`
+	Wildcard        bool              `json:"wildcard,omitempty"`
+	Insecure        bool              `json:"insecure,omitempty"`
+	Proxy           string            `json:"proxy,omitempty"`
+	Type            string            `json:"type,omitempty"`
+	Pool            *PoolConfig       `json:"pool,omitempty"`
+	Env             map[string]string `json:"env,omitempty"`
+}
+
+// PoolConfig holds the settings of a dedicated PHP-FPM pool for a site
+type PoolConfig struct {
+	MaxChildren     int               `json:"max_children"`
+	StartServers    int               `json:"start_servers"`
+	MinSpareServers int               `json:"min_spare_servers"`
+	MaxSpareServers int               `json:"max_spare_servers"`
+	Ini             map[string]string `json:"ini,omitempty"`
 }
 
 func GetWebConfig() *WebConfig {
@@ -10,6 +10,7 @@ const (
 	YerdPHPDir      = "/opt/yerd/php"
 	YerdEtcDir      = "/opt/yerd/etc"
 	YerdWebDir      = "/opt/yerd/web"
+	YerdCacheDir    = "/opt/yerd/cache"
 	SystemBinDir    = "/usr/local/bin"
 	GlobalPhpPath   = SystemBinDir + "/php"
 	SpinnerInterval = 200 * time.Millisecond
@@ -36,8 +37,29 @@ const (
 	// Error Messages
 	ErrEmptyPHPVersion = "PHP version cannot be empty"
 
+	// Keyrings used to verify the signatures of downloaded sources
+	KeyringDir   = YerdEtcDir + "/keyrings"
+	PhpKeyring   = KeyringDir + "/php.gpg"
+	NginxKeyring = KeyringDir + "/nginx.gpg"
+
+	// Config templates which replace those bundled with YERD
+	TemplatesDir = YerdEtcDir + "/templates"
+
+	// PHP Build Cache
+	BuildCacheDir = YerdCacheDir + "/builds"
+
+	// Downloaded sources and templates, kept for offline installs
+	SourceCacheDir = YerdCacheDir + "/sources"
+
 	// Web
-	CertsDir = YerdWebDir + "/certs"
+	CertsDir          = YerdWebDir + "/certs"
+	NginxSnippetsDir  = YerdWebDir + "/nginx/snippets"
+	NginxSitesEnabled = YerdWebDir + "/nginx/sites-enabled"
+
+	// DNS
+	DNSServiceName   = "yerd-dns"
+	DNSListenAddress = "127.0.0.1:5354"
+	DNSDomain        = "test"
 
 	// Config
 	YerdConfigName = "config.json"
@@ -7,6 +7,7 @@ type NginxConfig struct {
 	Name         string
 	Version      string
 	DownloadURL  string
+	Checksum     string
 	BuildFlags   []string
 	Dependencies []string
 	InstallPath  string
@@ -23,7 +24,10 @@ func GetNginxConfig() *NginxConfig {
 	return &NginxConfig{
 		Name:        "nginx",
 		Version:     "1.29.1",
-		DownloadURL: "http://nginx.org/download/nginx-1.29.1.tar.gz",
+		DownloadURL: "https://nginx.org/download/nginx-1.29.1.tar.gz",
+		// SHA-256 of the release archive, pinned with the version as
+		// nginx.org does not publish checksums, update both together
+		Checksum: "",
 		BuildFlags: []string{
 			"--prefix=/opt/yerd/web/nginx",
 			"--conf-path=/opt/yerd/web/nginx/conf/nginx.conf",
@@ -0,0 +1,14 @@
+package constants
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNginxChecksumIsPinned(t *testing.T) {
+	checksum := GetNginxConfig().Checksum
+
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(checksum) {
+		t.Fatalf("nginx checksum %q is not a SHA-256 hex digest, pin the digest of nginx-%s.tar.gz", checksum, GetNginxConfig().Version)
+	}
+}
@@ -2,8 +2,10 @@ package constants
 
 import (
 	"maps"
+	"regexp"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -15,7 +17,60 @@ type Extension struct {
 	PECLName     string
 }
 
-var availablePhpVersions = []string{"8.1", "8.2", "8.3", "8.4"}
+// PhpProfile describes how building a PHP release line differs from
+// the defaults, release lines without a profile use the defaults
+type PhpProfile struct {
+	// Legacy release lines are no longer supported by php.net
+	Legacy bool
+	// RemovedFlags are configure flags the release line does not accept
+	RemovedFlags []string
+	// ExtraFlags are configure flags the release line requires
+	ExtraFlags []string
+	// Extensions replaces extensions which are built differently
+	Extensions map[string]Extension
+	// PatchedExtensions only build once a source patch for the release
+	// line is present, the install stops before downloading without one
+	PatchedExtensions []string
+	// Notes are shown before the release line is built
+	Notes string
+}
+
+var peclImap = Extension{
+	Name:         "imap",
+	Dependencies: []string{"imap"},
+	IsPECL:       true,
+	PECLName:     "imap",
+}
+
+// phpProfiles are the release lines YERD knows how to build, they are
+// always available alongside those listed by the version catalogue
+var phpProfiles = map[string]PhpProfile{
+	"7.4": {
+		Legacy:            true,
+		PatchedExtensions: []string{"openssl"},
+		Notes:             "PHP 7.4 is no longer supported by php.net, its openssl extension needs a patch in /opt/yerd/etc/templates/php/patches/7.4 to build against OpenSSL 3",
+	},
+	"8.0": {
+		Legacy:            true,
+		RemovedFlags:      []string{"--enable-json"},
+		PatchedExtensions: []string{"openssl"},
+		Notes:             "PHP 8.0 is no longer supported by php.net, its openssl extension needs a patch in /opt/yerd/etc/templates/php/patches/8.0 to build against OpenSSL 3",
+	},
+	"8.1": {RemovedFlags: []string{"--enable-json"}},
+	"8.2": {RemovedFlags: []string{"--enable-json"}},
+	"8.3": {RemovedFlags: []string{"--enable-json"}},
+	"8.4": {
+		RemovedFlags: []string{"--enable-json"},
+		Extensions:   map[string]Extension{"imap": peclImap},
+	},
+	"8.5": {
+		RemovedFlags: []string{"--enable-json", "--enable-opcache"},
+		Extensions:   map[string]Extension{"imap": peclImap},
+	},
+}
+
+var availablePhpVersions = sortPhpVersions(slices.Collect(maps.Keys(phpProfiles)))
+var phpVersionPattern = regexp.MustCompile(`^\d+\.\d+$`)
 var availableExtensions = map[string]Extension{
 	"mbstring": {
 		Name:       "mbstring",
@@ -233,9 +288,65 @@ var defaultExtensions = []string{
 	"sockets", "zlib",
 }
 
-// GetAvailableVersions returns the list of PHP versions supported by YERD.
+// GetAvailableVersions returns the list of PHP versions supported by YERD,
+// oldest first, the list comes from the version catalogue when loaded
 func GetAvailablePhpVersions() []string {
-	return availablePhpVersions
+	return slices.Clone(availablePhpVersions)
+}
+
+// SetAvailablePhpVersions replaces the versions from the catalogue, the
+// release lines YERD has a profile for are always kept
+func SetAvailablePhpVersions(versions []string) {
+	merged := slices.Collect(maps.Keys(phpProfiles))
+	for _, version := range versions {
+		if IsPhpVersionFormat(version) && !slices.Contains(merged, version) {
+			merged = append(merged, version)
+		}
+	}
+
+	availablePhpVersions = sortPhpVersions(merged)
+}
+
+// IsPhpVersionFormat checks if version is a major.minor version, eg: 8.4
+func IsPhpVersionFormat(version string) bool {
+	return phpVersionPattern.MatchString(version)
+}
+
+// GetPhpProfile returns how the release line is built, versions without
+// a profile are built with the defaults
+func GetPhpProfile(version string) PhpProfile {
+	return phpProfiles[version]
+}
+
+// ComparePhpVersions compares two dotted version strings numerically,
+// returning 1 if a is newer, -1 if b is newer and 0 if they are equal
+func ComparePhpVersions(a, b string) int {
+	partsA := strings.Split(a, ".")
+	partsB := strings.Split(b, ".")
+
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+
+		if numA != numB {
+			if numA > numB {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	return 0
+}
+
+func sortPhpVersions(versions []string) []string {
+	slices.SortFunc(versions, ComparePhpVersions)
+	return versions
 }
 
 // IsValidVersion checks if the provided version string is supported by YERD.
@@ -266,6 +377,16 @@ func GetExtension(name string) (Extension, bool) {
 	return ext, exists
 }
 
+// GetVersionExtension retrieves extension information by name for a PHP
+// version, taking into account extensions built differently by the version
+func GetVersionExtension(name, version string) (Extension, bool) {
+	if ext, exists := GetPhpProfile(version).Extensions[name]; exists {
+		return ext, true
+	}
+
+	return GetExtension(name)
+}
+
 // ValidateExtensions separates provided extensions into valid and invalid lists.
 // extensions: Extension names to validate. Returns valid extensions slice and invalid extensions slice.
 func ValidateExtensions(extensions []string) ([]string, []string) {
@@ -284,13 +405,16 @@ func ValidateExtensions(extensions []string) ([]string, []string) {
 }
 
 // GetConfigureFlags returns PHP configure flags for the specified extensions.
-// extensions: Extension names to get flags for. Returns slice of configure flag strings.
-func GetExtensionConfigureFlags(extensions []string) []string {
+// version: PHP version being built, extensions: Extension names to get flags for.
+func GetExtensionConfigureFlags(version string, extensions []string) []string {
 	var flags []string
+	profile := GetPhpProfile(version)
 
 	for _, extName := range extensions {
-		if ext, exists := availableExtensions[extName]; exists {
-			flags = append(flags, ext.ConfigFlag)
+		if ext, exists := GetVersionExtension(extName, version); exists && ext.ConfigFlag != "" {
+			if !slices.Contains(profile.RemovedFlags, ext.ConfigFlag) {
+				flags = append(flags, ext.ConfigFlag)
+			}
 		}
 	}
 
@@ -0,0 +1,214 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+const (
+	ResolverSystemd        = "systemd-resolved"
+	ResolverNetworkManager = "NetworkManager"
+
+	resolvedDropIn       = "/etc/systemd/resolved.conf.d/yerd.conf"
+	networkManagerDropIn = "/etc/NetworkManager/dnsmasq.d/yerd.conf"
+	networkManagerConfig = "/etc/NetworkManager/NetworkManager.conf"
+	networkManagerConfD  = "/etc/NetworkManager/conf.d"
+)
+
+// Install creates and starts the yerd-dns service and configures the
+// system resolver to send *.test queries to it
+func Install() (string, error) {
+	resolver, err := DetectResolver()
+	if err != nil {
+		return "", err
+	}
+
+	if err := writeService(); err != nil {
+		return "", err
+	}
+
+	if err := configureResolver(resolver); err != nil {
+		return "", err
+	}
+
+	webConfig := config.GetWebConfig()
+	webConfig.DNS = true
+	config.SetStruct("web", webConfig)
+
+	return resolver, nil
+}
+
+// Uninstall stops the yerd-dns service and removes the resolver wiring
+func Uninstall() error {
+	utils.SystemdStopService(constants.DNSServiceName)
+	utils.SystemdDisable(constants.DNSServiceName)
+	utils.RemoveFile(filepath.Join(constants.SystemdDir, constants.DNSServiceName+".service"))
+	utils.SystemdReload()
+
+	if utils.FileExists(resolvedDropIn) {
+		utils.RemoveFile(resolvedDropIn)
+		restartService(ResolverSystemd)
+	}
+
+	if utils.FileExists(networkManagerDropIn) {
+		utils.RemoveFile(networkManagerDropIn)
+		restartService(ResolverNetworkManager)
+	}
+
+	webConfig := config.GetWebConfig()
+	if webConfig.DNS {
+		webConfig.DNS = false
+		config.SetStruct("web", webConfig)
+	}
+
+	return nil
+}
+
+// DetectResolver identifies which local resolver supports split DNS
+// Returns the resolver name or an error if none are supported
+func DetectResolver() (string, error) {
+	if utils.SystemdServiceActive(ResolverSystemd) {
+		return ResolverSystemd, nil
+	}
+
+	if utils.SystemdServiceActive(ResolverNetworkManager) && networkManagerUsesDnsmasq() {
+		return ResolverNetworkManager, nil
+	}
+
+	return "", fmt.Errorf("no supported resolver found, systemd-resolved or NetworkManager with dnsmasq is required")
+}
+
+// networkManagerUsesDnsmasq checks whether NetworkManager is configured
+// with dns=dnsmasq, reading its configuration in the order it does, so
+// a file in conf.d overrides NetworkManager.conf
+func networkManagerUsesDnsmasq() bool {
+	files := []string{networkManagerConfig}
+	if matches, err := filepath.Glob(filepath.Join(networkManagerConfD, "*.conf")); err == nil {
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	dnsMode := ""
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		if mode, found := networkManagerDnsMode(string(content)); found {
+			dnsMode = mode
+		}
+	}
+
+	return dnsMode == "dnsmasq"
+}
+
+// networkManagerDnsMode returns the dns value from the [main] section of
+// a NetworkManager configuration file
+func networkManagerDnsMode(content string) (string, bool) {
+	section := ""
+	mode, found := "", false
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if ok && section == "main" && strings.TrimSpace(key) == "dns" {
+			mode, found = strings.TrimSpace(value), true
+		}
+	}
+
+	return mode, found
+}
+
+// IsRunning checks whether the yerd-dns service is active
+func IsRunning() bool {
+	return utils.SystemdServiceActive(constants.DNSServiceName)
+}
+
+// writeService renders the systemd unit and starts the service
+func writeService() error {
+	binary, err := os.Executable()
+	if err != nil {
+		utils.LogError(err, "dns")
+		return fmt.Errorf("unable to locate the yerd binary")
+	}
+
+	content, err := utils.LoadTemplate("web", "dns.service")
+	if err != nil {
+		utils.LogError(err, "dns")
+		return fmt.Errorf("unable to load the dns.service template")
+	}
+
+	content, err = utils.Template(content, utils.TemplateData{
+		"binary": binary,
+		"domain": constants.DNSDomain,
+	})
+	if err != nil {
+		utils.LogError(err, "dns")
+		return fmt.Errorf("unable to render dns.service: %w", err)
+	}
+
+	servicePath := filepath.Join(constants.SystemdDir, constants.DNSServiceName+".service")
+	if err := utils.WriteStringToFile(servicePath, content, constants.FilePermissions); err != nil {
+		utils.LogError(err, "dns")
+		return err
+	}
+
+	if err := utils.SystemdReload(); err != nil {
+		return err
+	}
+
+	utils.SystemdStopService(constants.DNSServiceName)
+	if err := utils.SystemdStartService(constants.DNSServiceName); err != nil {
+		return err
+	}
+
+	return utils.SystemdEnable(constants.DNSServiceName)
+}
+
+// configureResolver writes the split DNS drop-in for the resolver
+func configureResolver(resolver string) error {
+	var path, content string
+
+	switch resolver {
+	case ResolverSystemd:
+		path = resolvedDropIn
+		content = fmt.Sprintf("[Resolve]\nDNS=%s\nDomains=~%s\n", constants.DNSListenAddress, constants.DNSDomain)
+	case ResolverNetworkManager:
+		host, port, _ := net.SplitHostPort(constants.DNSListenAddress)
+		path = networkManagerDropIn
+		content = fmt.Sprintf("server=/%s/%s#%s\n", constants.DNSDomain, host, port)
+	default:
+		return fmt.Errorf("unsupported resolver %s", resolver)
+	}
+
+	if err := utils.WriteStringToFile(path, content, constants.FilePermissions); err != nil {
+		utils.LogError(err, "dns")
+		return err
+	}
+
+	return restartService(resolver)
+}
+
+// restartService restarts a system service so it picks up new configuration
+func restartService(service string) error {
+	utils.SystemdStopService(service)
+	return utils.SystemdStartService(service)
+}
@@ -0,0 +1,228 @@
+package dns
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+const (
+	typeA   = 1
+	typeANY = 255
+	classIN = 1
+
+	rcodeSuccess  = 0
+	rcodeFormErr  = 1
+	rcodeRefused  = 5
+	headerLength  = 12
+	answerTTL     = 60
+	maxUDPMessage = 512
+)
+
+// Server is a minimal DNS responder which answers every name under
+// the configured domain, including arbitrary subdomains, with a
+// fixed IPv4 address
+type Server struct {
+	Address string
+	Domain  string
+	IP      net.IP
+}
+
+// NewServer creates a responder for *.domain resolving to 127.0.0.1
+// address: The address to listen on, eg: 127.0.0.1:5354
+// domain: The domain to answer for, eg: test
+func NewServer(address, domain string) *Server {
+	return &Server{
+		Address: address,
+		Domain:  strings.Trim(strings.ToLower(domain), "."),
+		IP:      net.ParseIP(utils.DefaultIP).To4(),
+	}
+}
+
+// ListenAndServe serves DNS over both UDP and TCP until either fails
+func (s *Server) ListenAndServe() error {
+	udpConn, err := net.ListenPacket("udp", s.Address)
+	if err != nil {
+		return fmt.Errorf("unable to listen on udp %s: %w", s.Address, err)
+	}
+	defer udpConn.Close()
+
+	tcpListener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return fmt.Errorf("unable to listen on tcp %s: %w", s.Address, err)
+	}
+	defer tcpListener.Close()
+
+	errs := make(chan error, 2)
+	go func() { errs <- s.serveUDP(udpConn) }()
+	go func() { errs <- s.serveTCP(tcpListener) }()
+
+	return <-errs
+}
+
+// serveUDP answers each datagram received on conn
+func (s *Server) serveUDP(conn net.PacketConn) error {
+	buffer := make([]byte, maxUDPMessage)
+	for {
+		n, addr, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return err
+		}
+
+		if response := s.handle(buffer[:n]); response != nil {
+			conn.WriteTo(response, addr)
+		}
+	}
+}
+
+// serveTCP accepts connections and answers length prefixed messages
+func (s *Server) serveTCP(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go s.handleTCP(conn)
+	}
+}
+
+// handleTCP answers messages on a single connection until it closes
+func (s *Server) handleTCP(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		var length uint16
+		if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+			return
+		}
+
+		message := make([]byte, length)
+		if _, err := io.ReadFull(conn, message); err != nil {
+			return
+		}
+
+		response := s.handle(message)
+		if response == nil {
+			return
+		}
+
+		prefix := make([]byte, 2)
+		binary.BigEndian.PutUint16(prefix, uint16(len(response)))
+		if _, err := conn.Write(append(prefix, response...)); err != nil {
+			return
+		}
+	}
+}
+
+// handle builds the response for a single DNS query message
+func (s *Server) handle(message []byte) []byte {
+	if len(message) < headerLength {
+		return nil
+	}
+
+	flags := binary.BigEndian.Uint16(message[2:4])
+	if flags&0x8000 != 0 {
+		// Ignore anything which is already a response
+		return nil
+	}
+
+	questionCount := binary.BigEndian.Uint16(message[4:6])
+	if questionCount != 1 {
+		return s.reply(message, headerLength, rcodeFormErr, false)
+	}
+
+	name, offset, ok := readName(message, headerLength)
+	if !ok || offset+4 > len(message) {
+		return s.reply(message, headerLength, rcodeFormErr, false)
+	}
+
+	qtype := binary.BigEndian.Uint16(message[offset : offset+2])
+	qclass := binary.BigEndian.Uint16(message[offset+2 : offset+4])
+	questionEnd := offset + 4
+
+	if !s.matches(name) {
+		return s.reply(message, questionEnd, rcodeRefused, false)
+	}
+
+	answer := qclass == classIN && (qtype == typeA || qtype == typeANY)
+	return s.reply(message, questionEnd, rcodeSuccess, answer)
+}
+
+// matches checks if name is the configured domain or a subdomain of it
+func (s *Server) matches(name string) bool {
+	name = strings.Trim(strings.ToLower(name), ".")
+	return name == s.Domain || strings.HasSuffix(name, "."+s.Domain)
+}
+
+// reply copies the header and question of the query and optionally
+// appends a single A record answer pointing at the question name
+func (s *Server) reply(query []byte, questionEnd, rcode int, answer bool) []byte {
+	response := make([]byte, questionEnd)
+	copy(response, query[:questionEnd])
+
+	requestFlags := binary.BigEndian.Uint16(query[2:4])
+	flags := uint16(0x8000) | (requestFlags & 0x7800) | 0x0400 | (requestFlags & 0x0100) | uint16(rcode)
+	binary.BigEndian.PutUint16(response[2:4], flags)
+
+	questions := uint16(1)
+	if questionEnd == headerLength {
+		questions = 0
+	}
+	binary.BigEndian.PutUint16(response[4:6], questions)
+	binary.BigEndian.PutUint16(response[6:8], 0)
+	binary.BigEndian.PutUint16(response[8:10], 0)
+	binary.BigEndian.PutUint16(response[10:12], 0)
+
+	if !answer {
+		return response
+	}
+
+	binary.BigEndian.PutUint16(response[6:8], 1)
+
+	record := make([]byte, 16)
+	binary.BigEndian.PutUint16(record[0:2], 0xC000|headerLength)
+	binary.BigEndian.PutUint16(record[2:4], typeA)
+	binary.BigEndian.PutUint16(record[4:6], classIN)
+	binary.BigEndian.PutUint32(record[6:10], answerTTL)
+	binary.BigEndian.PutUint16(record[10:12], 4)
+	copy(record[12:16], s.IP)
+
+	return append(response, record...)
+}
+
+// readName decodes an uncompressed domain name starting at offset,
+// returning the name and the offset immediately after it
+func readName(message []byte, offset int) (string, int, bool) {
+	labels := []string{}
+
+	for {
+		if offset >= len(message) {
+			return "", 0, false
+		}
+
+		length := int(message[offset])
+		offset++
+
+		if length == 0 {
+			break
+		}
+
+		// Compression pointers are never sent in a question
+		if length&0xC0 != 0 || offset+length > len(message) {
+			return "", 0, false
+		}
+
+		labels = append(labels, string(message[offset:offset+length]))
+		offset += length
+	}
+
+	return strings.Join(labels, "."), offset, true
+}
@@ -1,29 +1,54 @@
 package composer
 
 import (
+	"github.com/lumosolutions/yerd/internal/config"
 	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/installers/shim"
 	"github.com/lumosolutions/yerd/internal/utils"
 )
 
+// downloadComposer fetches the latest stable composer.phar, falling back
+// to the last copy fetched when offline
 func downloadComposer() error {
-	return utils.DownloadFile(
-		constants.ComposerDownloadUrl,
-		constants.LocalComposerPath,
-		nil,
-	)
+	content, err := utils.FetchSource(constants.ComposerDownloadUrl)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteToFile(constants.LocalComposerPath, content, constants.FilePermissions)
 }
 
 func InstallComposer() error {
 	return utils.RunAll(
 		func() error { return downloadComposer() },
 		func() error { return utils.Chmod(constants.LocalComposerPath, 0755) },
-		func() error { return utils.CreateSymlink(constants.LocalComposerPath, constants.GlobalComposerPath) },
+		func() error { return linkComposer() },
 	)
 }
 
+// linkComposer exposes composer globally, either as a symlink to the
+// phar or as a shim when per-project PHP versions are enabled
+func linkComposer() error {
+	shimConfig := config.GetShimConfig()
+	if shimConfig.Enabled {
+		return shim.WriteShim(constants.GlobalComposerPath, shimConfig.Binary, "composer")
+	}
+
+	return utils.CreateSymlink(constants.LocalComposerPath, constants.GlobalComposerPath)
+}
+
+// unlinkComposer removes the global composer symlink or shim
+func unlinkComposer() error {
+	if shim.IsShim(constants.GlobalComposerPath) {
+		return utils.RemoveFile(constants.GlobalComposerPath)
+	}
+
+	return utils.RemoveSymlink(constants.GlobalComposerPath)
+}
+
 func RemoveComposer() error {
 	return utils.RunAll(
-		func() error { return utils.RemoveSymlink(constants.GlobalComposerPath) },
+		func() error { return unlinkComposer() },
 		func() error { return utils.RemoveFile(constants.LocalComposerPath) },
 	)
 }
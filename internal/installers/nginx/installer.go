@@ -7,6 +7,7 @@ import (
 
 	"github.com/lumosolutions/yerd/internal/config"
 	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/dns"
 	"github.com/lumosolutions/yerd/internal/manager"
 	"github.com/lumosolutions/yerd/internal/utils"
 )
@@ -17,6 +18,7 @@ type NginxInstaller struct {
 	ForceConfig bool
 	Spinner     *utils.Spinner
 	DepManager  *manager.DependencyManager
+	createdDirs []string
 }
 
 func NewNginxInstaller(update, forceConfig bool) (*NginxInstaller, error) {
@@ -51,6 +53,12 @@ func (installer *NginxInstaller) Uninstall() error {
 		}
 	}
 
+	manager.RemoveParkWatcher()
+
+	if webConfig.DNS {
+		dns.Uninstall()
+	}
+
 	utils.SystemdStopService("yerd-nginx")
 	utils.SystemdDisable("yerd-nginx")
 
@@ -74,18 +82,19 @@ func (installer *NginxInstaller) Install() error {
 		return fmt.Errorf("already installed")
 	}
 
-	err := utils.RunAll(
-		func() error { return installer.installDependencies() },
-		func() error { return installer.prepareInstall() },
-		func() error { return installer.downloadSource() },
-		func() error { return installer.compileAndInstall() },
-		func() error { return installer.createCerts() },
-		func() error { return installer.addNginxConf() },
-		func() error { return installer.addSystemdService() },
-		func() error { return installer.writeConfig() },
+	err := utils.NewTransaction("nginx").Run(
+		utils.Step{Name: "dependencies", Do: installer.installDependencies},
+		utils.Step{Name: "directories", Do: installer.prepareInstall, Undo: installer.removeInstall},
+		utils.Step{Name: "download", Do: installer.downloadSource},
+		utils.Step{Name: "compile", Do: installer.compileAndInstall},
+		utils.Step{Name: "certificates", Do: installer.createCerts, Undo: installer.removeCerts},
+		utils.Step{Name: "nginx.conf", Do: installer.addNginxConf},
+		utils.Step{Name: "systemd", Do: installer.addSystemdService, Undo: installer.removeSystemdService},
+		utils.Step{Name: "configuration", Do: installer.writeConfig},
 	)
 
 	if err != nil {
+		installer.Spinner.StopWithError("Failed to install Web Components, all changes have been rolled back")
 		return err
 	}
 
@@ -97,7 +106,7 @@ func (installer *NginxInstaller) Install() error {
 func (installer *NginxInstaller) installDependencies() error {
 	installer.Spinner.UpdatePhrase("Installing Dependencies...")
 	if err := installer.DepManager.InstallWebDependencies(); err != nil {
-		installer.Spinner.StopWithError("Failed to install dependencies")
+		installer.Spinner.AddErrorStatus("Failed to install dependencies")
 		return err
 	}
 
@@ -118,9 +127,12 @@ func (installer *NginxInstaller) prepareInstall() error {
 	}
 
 	for _, dir := range requiredDirs {
+		if created := firstMissingDir(dir); created != "" {
+			installer.createdDirs = append(installer.createdDirs, created)
+		}
+
 		if err := utils.CreateDirectory(dir); err != nil {
 			installer.Spinner.AddErrorStatus("Failed to create directory: %s", dir)
-			installer.Spinner.StopWithError("Installation stopped failure in setup")
 			return err
 		}
 	}
@@ -130,24 +142,71 @@ func (installer *NginxInstaller) prepareInstall() error {
 	return nil
 }
 
+// removeInstall deletes the directories created by prepareInstall,
+// leaving any which existed beforehand untouched
+func (installer *NginxInstaller) removeInstall() error {
+	for i := len(installer.createdDirs) - 1; i >= 0; i-- {
+		if err := utils.RemoveFolder(installer.createdDirs[i]); err != nil {
+			return err
+		}
+	}
+
+	installer.createdDirs = nil
+	return nil
+}
+
+// firstMissingDir returns the outermost directory which creating dir
+// would add, or an empty string when dir already exists
+func firstMissingDir(dir string) string {
+	missing := ""
+	for current := filepath.Clean(dir); !utils.FileExists(current); current = filepath.Dir(current) {
+		missing = current
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	return missing
+}
+
 func (installer *NginxInstaller) downloadSource() error {
 	installer.Spinner.UpdatePhrase("Downloading Nginx")
 	archivePath := filepath.Join(os.TempDir(), "nginx.tar.gz")
-	if err := utils.DownloadFile(installer.Info.DownloadURL, archivePath, nil); err != nil {
+	if err := utils.DownloadSource(installer.Info.DownloadURL, archivePath); err != nil {
 		installer.Spinner.AddErrorStatus("Unable to download Nginx")
 		installer.Spinner.AddInfoStatus("- Error: %v", err)
-		installer.Spinner.StopWithError("Failed to download Nginx")
 		return err
 	}
 
+	if installer.Info.Checksum == "" {
+		os.Remove(archivePath)
+		installer.Spinner.AddErrorStatus("No SHA-256 is pinned for Nginx %s, the source cannot be verified", installer.Info.Version)
+		return fmt.Errorf("no checksum pinned for nginx %s", installer.Info.Version)
+	}
+
+	signed, err := utils.VerifyDownload(archivePath, installer.Info.Checksum, installer.Info.DownloadURL+".asc", constants.NginxKeyring)
+	if err != nil {
+		utils.ForgetSource(installer.Info.DownloadURL)
+		installer.Spinner.AddErrorStatus("- Error: %v", err)
+		installer.Spinner.AddErrorStatus("Nginx source failed verification, the download has been removed")
+		return err
+	}
+
+	installer.Spinner.AddSuccessStatus("Verified SHA-256 Checksum")
+	if signed {
+		installer.Spinner.AddSuccessStatus("Verified Signature")
+	} else {
+		installer.Spinner.AddWarningStatus("Signature not checked, no keyring at %s", constants.NginxKeyring)
+	}
+
 	userCtx, err := utils.GetRealUser()
 	if err != nil {
-		installer.Spinner.StopWithError("Failed to identify real user")
+		installer.Spinner.AddErrorStatus("Failed to identify real user")
 		return err
 	}
 
 	if err := utils.ExtractArchive(archivePath, installer.Info.SourcePath, userCtx); err != nil {
-		installer.Spinner.StopWithError("Failed to extract Nginx")
+		installer.Spinner.AddErrorStatus("Failed to extract Nginx")
 		return err
 	}
 
@@ -162,7 +221,7 @@ func (installer *NginxInstaller) compileAndInstall() error {
 	buildPath := filepath.Join(installer.Info.SourcePath, fmt.Sprintf("nginx-%s", installer.Info.Version))
 
 	if !utils.FileExists(filepath.Join(buildPath, "/configure")) {
-		installer.Spinner.StopWithError("No configure script for Nginx")
+		installer.Spinner.AddErrorStatus("No configure script for Nginx")
 		return fmt.Errorf("configure script not found in source directory")
 	}
 
@@ -173,7 +232,7 @@ func (installer *NginxInstaller) compileAndInstall() error {
 	)
 
 	if !success {
-		installer.Spinner.StopWithError("Unable to configure Nginx")
+		installer.Spinner.AddErrorStatus("Unable to configure Nginx")
 		return fmt.Errorf("unable to configure nginx")
 	}
 
@@ -187,7 +246,7 @@ func (installer *NginxInstaller) compileAndInstall() error {
 	)
 
 	if !success {
-		installer.Spinner.StopWithError("Unable to install Nginx")
+		installer.Spinner.AddErrorStatus("Unable to install Nginx")
 		return fmt.Errorf("unable to install nginx")
 	}
 
@@ -202,30 +261,33 @@ func (installer *NginxInstaller) addNginxConf() error {
 		return nil
 	}
 
-	installer.Spinner.UpdatePhrase("Downloading nginx.conf")
+	installer.Spinner.UpdatePhrase("Writing nginx.conf")
 
-	content, err := utils.FetchFromGitHub("nginx", "nginx.conf")
+	content, err := utils.LoadTemplate("nginx", "nginx.conf")
 	if err != nil {
 		utils.LogError(err, "addConf")
-		installer.Spinner.AddErrorStatus("Failed to download nginx configuration")
-		installer.Spinner.StopWithError("nginx.conf download failed")
+		installer.Spinner.AddErrorStatus("Failed to load the nginx configuration template")
 		return err
 	}
 
-	content = utils.Template(content, utils.TemplateData{
+	content, err = utils.Template(content, utils.TemplateData{
 		"user": "root",
 	})
+	if err != nil {
+		utils.LogError(err, "addConf")
+		installer.Spinner.AddErrorStatus("Failed to render nginx.conf")
+		return err
+	}
 
 	filePath := filepath.Join(installer.Info.ConfigPath, "nginx.conf")
 
 	err = utils.WriteStringToFile(filePath, content, constants.FilePermissions)
 	if err != nil {
 		utils.LogError(err, "addConf")
-		installer.Spinner.StopWithError("Failed to write to nginx.conf")
+		installer.Spinner.AddErrorStatus("Failed to write to nginx.conf")
 		return err
 	}
 
-	installer.Spinner.AddInfoStatus("- Downloaded Nginx.conf")
 	installer.Spinner.AddInfoStatus("- Stored Nginx.conf")
 	installer.Spinner.AddSuccessStatus("Nginx Configured Successfully")
 	return nil
@@ -240,11 +302,10 @@ func (installer *NginxInstaller) addSystemdService() error {
 	installer.Spinner.UpdatePhrase("Configuring Systemd")
 
 	systemdPath := filepath.Join(constants.SystemdDir, "yerd-nginx.service")
-	content, err := utils.FetchFromGitHub("nginx", "systemd.conf")
+	content, err := utils.LoadTemplate("nginx", "systemd.conf")
 	if err != nil {
 		utils.LogError(err, "systemd")
-		installer.Spinner.AddErrorStatus("Failed to download systemd configuration")
-		installer.Spinner.StopWithError("systemd.conf download failed")
+		installer.Spinner.AddErrorStatus("Failed to load the systemd configuration template")
 		return err
 	}
 
@@ -263,7 +324,7 @@ func (installer *NginxInstaller) addSystemdService() error {
 	utils.SystemdStopService(serviceName)
 	if err := utils.SystemdStartService(serviceName); err != nil {
 		utils.LogInfo("setupSystemd", "Unable to start service %s", serviceName)
-		installer.Spinner.StopWithError("Unable to start service %s", serviceName)
+		installer.Spinner.AddErrorStatus("Unable to start service %s", serviceName)
 		return fmt.Errorf("unable to start service %s", serviceName)
 	}
 
@@ -275,6 +336,22 @@ func (installer *NginxInstaller) addSystemdService() error {
 	return nil
 }
 
+// removeSystemdService stops and removes the yerd-nginx service
+func (installer *NginxInstaller) removeSystemdService() error {
+	if installer.IsUpdate && !installer.ForceConfig {
+		return nil
+	}
+
+	utils.SystemdStopService("yerd-nginx")
+	utils.SystemdDisable("yerd-nginx")
+
+	if err := utils.RemoveFile(filepath.Join(constants.SystemdDir, "yerd-nginx.service")); err != nil {
+		return err
+	}
+
+	return utils.SystemdReload()
+}
+
 func (installer *NginxInstaller) writeConfig() error {
 	installer.Spinner.UpdatePhrase("Writing YERD Configuration")
 
@@ -306,3 +383,12 @@ func (installer *NginxInstaller) createCerts() error {
 
 	return nil
 }
+
+// removeCerts deletes the root CA and removes it from the trust stores
+func (installer *NginxInstaller) removeCerts() error {
+	if err := utils.RemoveFolder(filepath.Join(constants.CertsDir, "ca")); err != nil {
+		return err
+	}
+
+	return installer.DepManager.RemoveTrust()
+}
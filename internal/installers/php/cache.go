@@ -0,0 +1,360 @@
+package php
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// CachedBuild is a compiled PHP installation prefix stored as a tarball
+type CachedBuild struct {
+	Name     string
+	Path     string
+	Version  string
+	Size     int64
+	Modified time.Time
+}
+
+// buildCacheName returns the file name of the cached build for a full
+// PHP version, its extensions and the system it was compiled on, the
+// extensions, FPM user and patches are hashed as they change the build
+func buildCacheName(fullVersion string, extensions []string, distro string) string {
+	sorted := slices.Clone(extensions)
+	sort.Strings(sorted)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n", strings.Join(sorted, ","))
+	fmt.Fprintf(hash, "%s:%s\n", utils.GetFPMUser(), utils.GetFPMGroup())
+	if digest := patchesDigest(majorMinorOf(fullVersion)); digest != "" {
+		fmt.Fprintf(hash, "%s\n", digest)
+	}
+
+	return fmt.Sprintf("php-%s-%s%s-%s-%s.tar.gz",
+		fullVersion,
+		distro,
+		distroVersion(),
+		runtime.GOARCH,
+		hex.EncodeToString(hash.Sum(nil))[:12],
+	)
+}
+
+// distroVersion returns the VERSION_ID of the distribution, builds are
+// only reused on the same release as they link against its libraries
+func distroVersion() string {
+	content, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "VERSION_ID=") {
+			return strings.Trim(strings.TrimPrefix(line, "VERSION_ID="), `"`)
+		}
+	}
+
+	return ""
+}
+
+// checksumSuffix is appended to the name of a cached build for the
+// sidecar file holding its SHA-256, in the format written by sha256sum
+const checksumSuffix = ".sha256"
+
+// fetchCachedBuild returns the path of the named build in the local
+// cache, downloading or copying it from the mirror when it is missing.
+// A build compiled on this machine is verified against the digest
+// recorded when it was saved, wherever it is fetched from. Any other
+// build is verified against the .sha256 file published next to it,
+// which comes from the same mirror as the build, so this only detects
+// corruption and not a tampered mirror: only use a mirror you trust.
+// A local build which fails verification is dropped from the cache
+func fetchCachedBuild(name string) (string, bool) {
+	path := filepath.Join(constants.BuildCacheDir, name)
+	if utils.FileExists(path) {
+		err := verifyCachedBuild(name, path, path+checksumSuffix)
+		if err == nil {
+			return path, true
+		}
+
+		utils.LogWarning("buildcache", "Dropping %s from the cache: %v", name, err)
+		removeCachedBuild(path)
+	}
+
+	mirror := config.GetBuildCacheConfig().Mirror
+	if mirror == "" {
+		return "", false
+	}
+
+	if err := utils.CreateDirectory(constants.BuildCacheDir); err != nil {
+		return "", false
+	}
+
+	part := path + ".part"
+	defer removeCachedBuild(part)
+
+	for _, file := range []string{name + checksumSuffix, name} {
+		target := part
+		if strings.HasSuffix(file, checksumSuffix) {
+			target = part + checksumSuffix
+		}
+
+		if err := fetchFromMirror(mirror, file, target); err != nil {
+			utils.LogDebug("buildcache", "Unable to fetch %s from the mirror: %v", file, err)
+			return "", false
+		}
+	}
+
+	if err := verifyCachedBuild(name, part, part+checksumSuffix); err != nil {
+		utils.LogWarning("buildcache", "Rejecting %s from the mirror: %v", name, err)
+		return "", false
+	}
+
+	if err := os.Rename(part+checksumSuffix, path+checksumSuffix); err != nil {
+		return "", false
+	}
+
+	if err := os.Rename(part, path); err != nil {
+		return "", false
+	}
+
+	return path, true
+}
+
+// fetchFromMirror downloads or copies file from the mirror to target
+func fetchFromMirror(mirror, file, target string) error {
+	if isRemoteMirror(mirror) {
+		return utils.DownloadFile(strings.TrimRight(mirror, "/")+"/"+file, target, nil)
+	}
+
+	source := filepath.Join(mirror, file)
+	if !utils.FileExists(source) {
+		return fmt.Errorf("%s does not exist", source)
+	}
+
+	return utils.Copy(source, target)
+}
+
+// verifyCachedBuild checks the build at path against the digest recorded
+// when the named build was saved on this machine, or failing that the
+// SHA-256 in its checksum file, a build without either is never trusted
+func verifyCachedBuild(name, path, checksumPath string) error {
+	if digest := config.GetBuildCacheConfig().Digests[name]; digest != "" {
+		return utils.VerifyChecksum(path, digest)
+	}
+
+	utils.LogWarning("buildcache", "%s was not built on this machine, verifying it against its published checksum", name)
+
+	content, err := os.ReadFile(checksumPath)
+	if err != nil {
+		return fmt.Errorf("no checksum for %s: %w", filepath.Base(path), err)
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return fmt.Errorf("no checksum for %s", filepath.Base(path))
+	}
+
+	return utils.VerifyChecksum(path, fields[0])
+}
+
+// removeCachedBuild removes a cached build and its checksum file
+func removeCachedBuild(path string) {
+	os.Remove(path)
+	os.Remove(path + checksumSuffix)
+}
+
+// storeCachedBuild archives an installed PHP prefix into the local cache
+// and, when the mirror is a directory, shares it there as well
+func storeCachedBuild(name, version string) error {
+	if err := utils.CreateDirectory(constants.BuildCacheDir); err != nil {
+		return err
+	}
+
+	path := filepath.Join(constants.BuildCacheDir, name)
+	output, success := utils.ExecuteCommand("tar", "-czf", path+".part", "-C", constants.YerdPHPDir, "php"+version)
+	if !success {
+		os.Remove(path + ".part")
+		utils.LogDebug("buildcache", "%s", output)
+		return fmt.Errorf("unable to archive php%s", version)
+	}
+
+	checksum, err := utils.FileChecksum(path + ".part")
+	if err != nil {
+		os.Remove(path + ".part")
+		return err
+	}
+
+	if err := utils.WriteStringToFile(path+checksumSuffix, fmt.Sprintf("%s  %s\n", checksum, name), constants.FilePermissions); err != nil {
+		os.Remove(path + ".part")
+		return err
+	}
+
+	if err := os.Rename(path+".part", path); err != nil {
+		removeCachedBuild(path)
+		return err
+	}
+
+	if err := config.SetStringData(fmt.Sprintf("build_cache.digests.[%s]", name), checksum); err != nil {
+		utils.LogWarning("buildcache", "Unable to record the digest of %s: %v", name, err)
+	}
+
+	mirror := config.GetBuildCacheConfig().Mirror
+	if mirror != "" && !isRemoteMirror(mirror) && utils.IsDirectory(mirror) {
+		for _, file := range []string{name, name + checksumSuffix} {
+			if err := utils.Copy(filepath.Join(constants.BuildCacheDir, file), filepath.Join(mirror, file)); err != nil {
+				utils.LogWarning("buildcache", "Unable to share %s with the mirror: %v", file, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// restoreCachedBuild replaces the installation prefix of version with
+// the contents of a cached build. The build is extracted into a
+// temporary directory and only moved into place once extracted, a build
+// with entries outside of php<version>/ is rejected and dropped
+func restoreCachedBuild(path, version string) error {
+	prefix := "php" + version
+
+	if err := checkCachedBuildEntries(path, prefix); err != nil {
+		removeCachedBuild(path)
+		return err
+	}
+
+	staging, err := os.MkdirTemp(constants.YerdPHPDir, ".restore-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	output, success := utils.ExecuteCommand("tar", "-xzf", path, "-C", staging, "--no-same-owner", prefix)
+	if !success {
+		utils.LogDebug("buildcache", "%s", output)
+		return fmt.Errorf("unable to extract %s", filepath.Base(path))
+	}
+
+	target := filepath.Join(constants.YerdPHPDir, prefix)
+	if err := utils.RemoveFolder(target); err != nil {
+		return err
+	}
+
+	return os.Rename(filepath.Join(staging, prefix), target)
+}
+
+// checkCachedBuildEntries reads every entry of the build at path and
+// rejects any which is not a file, directory or link within prefix
+func checkCachedBuildEntries(archivePath, prefix string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", filepath.Base(archivePath), err)
+	}
+	defer gz.Close()
+
+	installPrefix := path.Join(filepath.ToSlash(constants.YerdPHPDir), prefix)
+	within := func(name, root string) bool {
+		return name == root || strings.HasPrefix(name, root+"/")
+	}
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", filepath.Base(archivePath), err)
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(header.Name) || !within(name, prefix) {
+			return fmt.Errorf("%s contains %s, which is outside of %s/", filepath.Base(archivePath), header.Name, prefix)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeDir:
+		case tar.TypeSymlink:
+			target := header.Linkname
+			if path.IsAbs(target) {
+				if !within(path.Clean(target), installPrefix) {
+					return fmt.Errorf("%s links %s outside of %s/", filepath.Base(archivePath), header.Name, prefix)
+				}
+			} else if !within(path.Join(path.Dir(name), target), prefix) {
+				return fmt.Errorf("%s links %s outside of %s/", filepath.Base(archivePath), header.Name, prefix)
+			}
+		case tar.TypeLink:
+			if !within(path.Clean(header.Linkname), prefix) {
+				return fmt.Errorf("%s links %s outside of %s/", filepath.Base(archivePath), header.Name, prefix)
+			}
+		default:
+			return fmt.Errorf("%s contains %s, which is not a file, directory or link", filepath.Base(archivePath), header.Name)
+		}
+	}
+}
+
+// ListCachedBuilds returns every build in the local cache, newest first
+func ListCachedBuilds() ([]CachedBuild, error) {
+	entries, err := os.ReadDir(constants.BuildCacheDir)
+	if os.IsNotExist(err) {
+		return []CachedBuild{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	builds := []CachedBuild{}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".tar.gz") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		version := strings.SplitN(strings.TrimPrefix(entry.Name(), "php-"), "-", 2)[0]
+		builds = append(builds, CachedBuild{
+			Name:     entry.Name(),
+			Path:     filepath.Join(constants.BuildCacheDir, entry.Name()),
+			Version:  version,
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+		})
+	}
+
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].Modified.After(builds[j].Modified)
+	})
+
+	return builds, nil
+}
+
+// ClearBuildCache removes every cached build, the mirror is left alone
+func ClearBuildCache() error {
+	return utils.RemoveFolder(constants.BuildCacheDir)
+}
+
+func isRemoteMirror(mirror string) bool {
+	return strings.HasPrefix(mirror, "http://") || strings.HasPrefix(mirror, "https://")
+}
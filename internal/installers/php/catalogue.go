@@ -0,0 +1,213 @@
+package php
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+const (
+	PHPSupportedURL            = PHPReleasesURL + "8"
+	PHPPrereleasesURL          = utils.PhpSourceBase + "/release-candidates.php?format=json"
+	CatalogueValidDuration     = 24 * time.Hour
+	catalogueConfigKey         = "versions.php.catalogue"
+	prereleaseArchiveExtension = "gz"
+)
+
+var fullVersionPattern = regexp.MustCompile(`^(\d+\.\d+)\.\d+`)
+
+// PhpCatalogue lists the PHP release lines available to install, those
+// supported by php.net and those with a pre-release under test
+type PhpCatalogue struct {
+	LastUpdated time.Time                `json:"last_updated"`
+	Supported   []string                 `json:"supported"`
+	Prereleases map[string]PhpPrerelease `json:"prereleases"`
+}
+
+// PhpPrerelease is the newest alpha, beta or release candidate of a
+// release line, keyed in the catalogue by major.minor version
+type PhpPrerelease struct {
+	Version     string `json:"version"`
+	DownloadURL string `json:"download_url"`
+	Checksum    string `json:"checksum"`
+}
+
+// prereleaseFeed is the release candidate feed published by php.net
+type prereleaseFeed struct {
+	Releases []struct {
+		Active  bool   `json:"active"`
+		Version string `json:"version"`
+		Release struct {
+			Version  string `json:"version"`
+			SHA256Gz string `json:"sha256_gz"`
+		} `json:"release"`
+		Files map[string]struct {
+			Path   string `json:"path"`
+			SHA256 string `json:"sha256"`
+		} `json:"files"`
+	} `json:"releases"`
+}
+
+// Versions returns every release line in the catalogue
+func (catalogue *PhpCatalogue) Versions() []string {
+	versions := slices.Clone(catalogue.Supported)
+	for version := range catalogue.Prereleases {
+		if !slices.Contains(versions, version) {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions
+}
+
+// IsSupported reports whether php.net still supports the release line
+func (catalogue *PhpCatalogue) IsSupported(version string) bool {
+	return slices.Contains(catalogue.Supported, version)
+}
+
+// FindPrerelease returns the pre-release with the full version given,
+// eg: 8.5.0RC2
+func (catalogue *PhpCatalogue) FindPrerelease(fullVersion string) (PhpPrerelease, bool) {
+	for _, prerelease := range catalogue.Prereleases {
+		if prerelease.Version == fullVersion {
+			return prerelease, true
+		}
+	}
+
+	return PhpPrerelease{}, false
+}
+
+// GetCatalogue returns the cached catalogue, which is empty when the
+// catalogue has never been fetched
+func GetCatalogue() *PhpCatalogue {
+	var catalogue *PhpCatalogue
+	if err := config.GetStruct(catalogueConfigKey, &catalogue); err != nil || catalogue == nil {
+		catalogue = &PhpCatalogue{}
+	}
+
+	return catalogue
+}
+
+// LoadCatalogue makes the release lines in the cached catalogue available
+// without contacting php.net, the catalogue is refreshed alongside the
+// latest versions
+func LoadCatalogue() {
+	constants.SetAvailablePhpVersions(GetCatalogue().Versions())
+}
+
+// RefreshCatalogue fetches the supported release lines and pre-releases
+// from php.net and caches them, unless the cache is recent and force is
+// false, the cached catalogue is returned when php.net cannot be reached
+func RefreshCatalogue(force bool) (*PhpCatalogue, error) {
+	cached := GetCatalogue()
+	if !force && time.Since(cached.LastUpdated) < CatalogueValidDuration {
+		constants.SetAvailablePhpVersions(cached.Versions())
+		return cached, nil
+	}
+
+	supported, err := fetchSupportedVersions()
+	if err != nil {
+		return cached, err
+	}
+
+	prereleases, err := fetchPrereleases()
+	if err != nil {
+		utils.LogWarning("catalogue", "Unable to fetch pre-releases: %v", err)
+		prereleases = cached.Prereleases
+	}
+
+	catalogue := &PhpCatalogue{
+		LastUpdated: time.Now(),
+		Supported:   supported,
+		Prereleases: prereleases,
+	}
+
+	if err := config.SetStruct(catalogueConfigKey, catalogue); err != nil {
+		utils.LogWarning("catalogue", "Unable to save the catalogue: %v", err)
+	}
+
+	constants.SetAvailablePhpVersions(catalogue.Versions())
+	return catalogue, nil
+}
+
+// fetchSupportedVersions returns the release lines php.net supports
+func fetchSupportedVersions() ([]string, error) {
+	content, err := utils.FetchSource(PHPSupportedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var release PHPReleaseResponse
+	if err := json.Unmarshal(content, &release); err != nil {
+		return nil, fmt.Errorf("JSON decode failed: %v", err)
+	}
+
+	if len(release.SupportedVersions) == 0 {
+		return nil, fmt.Errorf("php.net did not list any supported versions")
+	}
+
+	return release.SupportedVersions, nil
+}
+
+// fetchPrereleases returns the newest active pre-release of each release
+// line from the php.net release candidate feed
+func fetchPrereleases() (map[string]PhpPrerelease, error) {
+	content, err := utils.FetchSource(PHPPrereleasesURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed prereleaseFeed
+	if err := json.Unmarshal(content, &feed); err != nil {
+		return nil, fmt.Errorf("JSON decode failed: %v", err)
+	}
+
+	prereleases := make(map[string]PhpPrerelease)
+	for _, release := range feed.Releases {
+		version := release.Version
+		if version == "" {
+			version = release.Release.Version
+		}
+
+		file, hasFile := release.Files[prereleaseArchiveExtension]
+		majorMinor := majorMinorOf(version)
+		if !release.Active || !hasFile || majorMinor == "" {
+			continue
+		}
+
+		checksum := file.SHA256
+		if checksum == "" {
+			checksum = release.Release.SHA256Gz
+		}
+
+		if checksum == "" {
+			utils.LogWarning("catalogue", "Skipping %s, no sha256 published", version)
+			continue
+		}
+
+		prereleases[majorMinor] = PhpPrerelease{
+			Version:     version,
+			DownloadURL: file.Path,
+			Checksum:    checksum,
+		}
+	}
+
+	return prereleases, nil
+}
+
+// majorMinorOf returns the release line of a full version, eg: 8.5 for
+// 8.5.0RC2, or an empty string when version is not a full version
+func majorMinorOf(fullVersion string) string {
+	match := fullVersionPattern.FindStringSubmatch(fullVersion)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
@@ -12,9 +12,13 @@ func SetCliVersion(info *config.PhpInfo) error {
 	binaryPath := getBinaryPath(info.Version)
 	globalPath := constants.GlobalPhpPath
 
-	if err := utils.CreateSymlink(binaryPath, globalPath); err != nil {
-		utils.LogError(err, "setcli")
-		return err
+	// When shims are enabled the global php command resolves the version
+	// itself, so only the configuration needs to record the new default
+	if !config.GetShimConfig().Enabled {
+		if err := utils.CreateSymlink(binaryPath, globalPath); err != nil {
+			utils.LogError(err, "setcli")
+			return err
+		}
 	}
 
 	info.IsCLI = true
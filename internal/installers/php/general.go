@@ -3,13 +3,10 @@ package php
 import (
 	"fmt"
 	"os"
-	"os/user"
 	"path/filepath"
-	"strconv"
 
 	"github.com/lumosolutions/yerd/internal/config"
 	"github.com/lumosolutions/yerd/internal/constants"
-	"github.com/lumosolutions/yerd/internal/utils"
 )
 
 // getPhpVersionInfo returns the version information for a PHP version
@@ -30,6 +27,15 @@ func getPhpVersionInfo(version string, cached bool) (*PhpVersionInfo, error) {
 		return nil, err
 	}
 
+	if versions[version] == "" {
+		return nil, fmt.Errorf("PHP %s has not been released yet", version)
+	}
+
+	checksum, err := getChecksum(versions[version])
+	if err != nil {
+		return nil, err
+	}
+
 	info, installed := config.GetInstalledPhpInfo(version)
 	var extensions []string
 	if installed {
@@ -44,6 +50,7 @@ func getPhpVersionInfo(version string, cached bool) (*PhpVersionInfo, error) {
 		MajorMinor:     version,
 		Version:        versions[version],
 		DownloadURL:    urls[versions[version]],
+		Checksum:       checksum,
 		ConfigureFlags: getConfigureFlags(version, extensions),
 		SourcePackage:  fmt.Sprintf("php-%s", version),
 		ArchivePath:    filepath.Join(tempDir, fmt.Sprintf("php-%s.tar.gz", versions[version])),
@@ -52,31 +59,15 @@ func getPhpVersionInfo(version string, cached bool) (*PhpVersionInfo, error) {
 	}, nil
 }
 
-// GetFPMUser returns the username that should be used for PHP-FPM processes.
-// Uses the real user context (handling sudo scenarios) or falls back to "nobody".
-func GetFPMUser() string {
-	userCtx, err := utils.GetRealUser()
-	if err != nil {
-		return "nobody"
-	}
-	return userCtx.Username
-}
-
-// GetFPMGroup returns the group name that should be used for PHP-FPM processes.
-// Uses the real user's primary group (handling sudo scenarios) or falls back to "nobody".
-func GetFPMGroup() string {
-	userCtx, err := utils.GetRealUser()
-	if err != nil {
-		return "nobody"
-	}
-
-	// Get group information from GID
-	group, err := user.LookupGroupId(strconv.Itoa(userCtx.GID))
-	if err != nil {
-		return "nobody"
+// getChecksum returns the SHA-256 published by php.net for a full PHP
+// version, from the version cache when it holds it
+func getChecksum(fullVersion string) (string, error) {
+	if cache, valid := GetCachedVersions(); valid && cache.Checksums[fullVersion] != "" {
+		return cache.Checksums[fullVersion], nil
 	}
 
-	return group.Name
+	_, _, checksum, err := FetchSpecificVersion(fullVersion)
+	return checksum, err
 }
 
 func getBinaryPath(version string) string {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,10 +23,18 @@ type PhpInstaller struct {
 	useExactVersion bool
 	exactVersion    string
 	extensions      []string
+	useBuildCache   bool
+	fromBuildCache  bool
+	cachedBuild     string
+	builtFromSource bool
+	downloadFailed  bool
 	spinner         *utils.Spinner
 	depManager      *manager.DependencyManager
 	installPath     string
-	err             error
+	previousInstall string
+	createdSymlinks []string
+	createdConfig   bool
+	replacedFiles   map[string][]byte
 }
 
 func NewPhpInstaller(version string, useCache, updateConfig bool) (*PhpInstaller, error) {
@@ -48,12 +57,13 @@ func NewPhpInstaller(version string, useCache, updateConfig bool) (*PhpInstaller
 	s.SetDelay(150)
 
 	return &PhpInstaller{
-		version:      version,
-		spinner:      s,
-		update:       update,
-		useCache:     useCache,
-		updateConfig: updateConfig,
-		extensions:   extensions,
+		version:       version,
+		spinner:       s,
+		update:        update,
+		useCache:      useCache,
+		updateConfig:  updateConfig,
+		extensions:    extensions,
+		useBuildCache: !config.GetBuildCacheConfig().Disabled,
 	}, nil
 }
 
@@ -67,27 +77,63 @@ func (installer *PhpInstaller) Install() error {
 	utils.PrintExtensionsGrid(installer.extensions)
 	fmt.Println()
 
+	if notes := constants.GetPhpProfile(installer.version).Notes; notes != "" {
+		fmt.Printf("⚠️  %s\n\n", notes)
+	}
+
 	installer.spinner.Start()
 
-	installer.
-		run(installer.identifySystem).
-		run(installer.getVersionInfo).
-		run(installer.conflictingBinaries).
-		run(installer.installDeps).
-		//run(installer.installPECLExtensions).
-		run(installer.downloadPhp).
-		run(installer.configurePhp).
-		run(installer.compilePhp).
-		run(installer.makePhp).
-		run(installer.installPECLExtensions).
-		run(installer.createSymlinks).
-		run(installer.verifyInstall).
-		run(installer.createDefaultConfig).
-		run(installer.setupSystemdService).
-		run(installer.writeConfig)
-
-	if installer.err != nil {
-		return installer.err
+	// Steps run in stages sharing one transaction, so a failure in any
+	// stage rolls back everything the earlier stages changed
+	transaction := utils.NewTransaction("php")
+
+	err := transaction.Run(
+		utils.Step{Name: "required patches", Do: installer.checkPatches},
+		utils.Step{Name: "identify system", Do: installer.identifySystem},
+		utils.Step{Name: "version info", Do: installer.getVersionInfo},
+		utils.Step{Name: "conflicting binaries", Do: installer.conflictingBinaries},
+		utils.Step{Name: "dependencies", Do: installer.installDeps},
+		utils.Step{Name: "find cached build", Do: installer.findCachedBuild},
+	)
+
+	if err == nil && installer.cachedBuild == "" {
+		err = transaction.Run(installer.buildSteps()...)
+	}
+
+	if err == nil {
+		err = transaction.Run(
+			utils.Step{Name: "previous install", Do: installer.preserveInstall, Undo: installer.restorePreviousInstall},
+			utils.Step{Name: "restore cached build", Do: installer.restoreFromBuildCache},
+		)
+	}
+
+	if err == nil && !installer.fromBuildCache && !installer.builtFromSource {
+		err = transaction.Run(installer.buildSteps()...)
+	}
+
+	if err == nil && !installer.fromBuildCache {
+		err = transaction.Run(utils.Step{Name: "make install", Do: installer.makePhp})
+	}
+
+	if err == nil {
+		err = transaction.Run(
+			utils.Step{Name: "pecl extensions", Do: installer.installPECLExtensions},
+			utils.Step{Name: "save cached build", Do: installer.saveToBuildCache},
+			utils.Step{Name: "symlinks", Do: installer.createSymlinks, Undo: installer.removeCreatedSymlinks},
+			utils.Step{Name: "verify", Do: installer.verifyInstall},
+			utils.Step{Name: "configuration", Do: installer.createDefaultConfig, Undo: installer.restoreDefaultConfig},
+			utils.Step{Name: "systemd", Do: installer.setupSystemdService, Undo: installer.removeSystemdService},
+			utils.Step{Name: "save configuration", Do: installer.writeConfig},
+			utils.Step{Name: "discard previous install", Do: installer.discardPreviousInstall},
+		)
+	}
+
+	if err != nil {
+		installer.spinner.StopWithError("Failed to install PHP %s, all changes have been rolled back", installer.version)
+		if installer.downloadFailed {
+			PrintVersionFetchError(installer.version)
+		}
+		return err
 	}
 
 	installer.spinner.StopWithSuccess("PHP %s Installed", installer.version)
@@ -95,12 +141,25 @@ func (installer *PhpInstaller) Install() error {
 	return nil
 }
 
-func (installer *PhpInstaller) run(fn func() error) *PhpInstaller {
-	if installer.err == nil {
-		installer.err = fn()
+// buildSteps returns the steps which compile PHP from source, the
+// extracted source is removed again if a later step fails
+func (installer *PhpInstaller) buildSteps() []utils.Step {
+	return []utils.Step{
+		{Name: "download", Do: installer.downloadPhp, Undo: installer.removeSource},
+		{Name: "patches", Do: installer.applyPatches},
+		{Name: "configure", Do: installer.configurePhp},
+		{Name: "compile", Do: installer.compilePhp},
 	}
+}
 
-	return installer
+// prefixPath returns the directory PHP is installed into
+func (installer *PhpInstaller) prefixPath() string {
+	return filepath.Join(constants.YerdPHPDir, "php"+installer.version)
+}
+
+// serviceName returns the name of the PHP-FPM systemd service
+func (installer *PhpInstaller) serviceName() string {
+	return fmt.Sprintf("yerd-php%s-fpm", installer.version)
 }
 
 func (installer *PhpInstaller) identifySystem() error {
@@ -108,7 +167,7 @@ func (installer *PhpInstaller) identifySystem() error {
 
 	manager, err := manager.NewDependencyManager()
 	if err != nil {
-		installer.spinner.StopWithError("Unable to identify the system")
+		installer.spinner.AddErrorStatus("Unable to identify the system")
 		return fmt.Errorf("failed to create dependency manager")
 	}
 
@@ -132,26 +191,40 @@ func (i *PhpInstaller) isExtensionLoaded(extName string) bool {
 	return strings.Contains(modules, strings.ToLower(extName))
 }
 
+// hasSharedExtension checks whether the extension has already been
+// compiled into the prefix, as it is when restored from the build cache
+func (i *PhpInstaller) hasSharedExtension(extName string) bool {
+	phpConfig := filepath.Join(constants.YerdPHPDir, fmt.Sprintf("php%s", i.version), "bin", "php-config")
+	output, success := utils.ExecuteCommand(phpConfig, "--extension-dir")
+	if !success {
+		return false
+	}
+
+	return utils.FileExists(filepath.Join(strings.TrimSpace(output), extName+".so"))
+}
+
 func (i *PhpInstaller) installPECLExtensions() error {
 	i.spinner.UpdatePhrase("Installing PECL extensions...")
 	peclPath := filepath.Join(constants.YerdPHPDir, fmt.Sprintf("php%s", i.version), "bin", "pecl")
 	for _, extName := range i.extensions {
-		if ext, exists := constants.GetExtension(extName); exists && ext.IsPECL {
+		if ext, exists := constants.GetVersionExtension(extName, i.version); exists && ext.IsPECL {
 			if i.isExtensionLoaded(extName) {
 				i.spinner.AddInfoStatus("Extension %s is already installed and loaded", extName)
 				continue
 			}
 
-			_, success := utils.ExecuteCommand(peclPath, "install", ext.PECLName)
-			if !success {
-				i.spinner.StopWithError("Failed to install PECL extension %s", extName)
-				return fmt.Errorf("failed to install PECL extension %s", extName)
+			if !i.hasSharedExtension(extName) {
+				_, success := utils.ExecuteCommand(peclPath, "install", ext.PECLName)
+				if !success {
+					i.spinner.AddErrorStatus("Failed to install PECL extension %s", extName)
+					return fmt.Errorf("failed to install PECL extension %s", extName)
+				}
 			}
 
 			iniPath := fmt.Sprintf("/opt/yerd/etc/php%s/conf.d/%s.ini", i.version, extName)
 			content := fmt.Sprintf("extension=%s.so\n", extName)
 			if err := utils.WriteStringToFile(iniPath, content, constants.FilePermissions); err != nil {
-				i.spinner.StopWithError("Failed to create ini file for extension %s", extName)
+				i.spinner.AddErrorStatus("Failed to create ini file for extension %s", extName)
 				return fmt.Errorf("failed to create ini file for %s: %v", extName, err)
 			}
 
@@ -166,14 +239,14 @@ func (installer *PhpInstaller) installDeps() error {
 	installer.spinner.UpdatePhrase("Installing Dependencies...")
 
 	if err := installer.depManager.InstallBuildDependencies(); err != nil {
-		installer.spinner.StopWithError("Failed to install build dependencies")
+		installer.spinner.AddErrorStatus("Failed to install build dependencies")
 		return err
 	}
 
 	installer.spinner.AddSuccessStatus("Installed Build Dependencies")
 
 	if err := installer.depManager.InstallExtensionDependencies(installer.extensions); err != nil {
-		installer.spinner.StopWithError("Failed to install extension dependencies")
+		installer.spinner.AddErrorStatus("Failed to install extension dependencies")
 		return err
 	}
 
@@ -181,31 +254,175 @@ func (installer *PhpInstaller) installDeps() error {
 	return nil
 }
 
+// findCachedBuild looks for a previously compiled build of the same PHP
+// version and extensions, in the local cache or the mirror
+func (installer *PhpInstaller) findCachedBuild() error {
+	if !installer.useBuildCache {
+		return nil
+	}
+
+	installer.spinner.UpdatePhrase("Checking Build Cache...")
+
+	name := buildCacheName(installer.info.Version, installer.extensions, installer.depManager.GetDistro())
+	path, found := fetchCachedBuild(name)
+	if !found {
+		installer.spinner.AddInfoStatus("No cached build, compiling from source")
+		return nil
+	}
+
+	installer.cachedBuild = path
+	return nil
+}
+
+// restoreFromBuildCache installs the cached build found earlier, PHP is
+// compiled from source instead when it cannot be restored
+func (installer *PhpInstaller) restoreFromBuildCache() error {
+	if installer.cachedBuild == "" {
+		return nil
+	}
+
+	if err := restoreCachedBuild(installer.cachedBuild, installer.version); err != nil {
+		utils.LogError(err, "buildcache")
+		installer.spinner.AddWarningStatus("Unable to restore the cached build, compiling from source")
+		return nil
+	}
+
+	installer.installPath = installer.prefixPath()
+	installer.fromBuildCache = true
+	installer.spinner.AddSuccessStatus("Restored PHP From Build Cache")
+
+	return nil
+}
+
+// preserveInstall moves an existing installation of the version aside,
+// so it can be put back if the new installation fails
+func (installer *PhpInstaller) preserveInstall() error {
+	prefix := installer.prefixPath()
+	if !utils.IsDirectory(prefix) {
+		return nil
+	}
+
+	previous := prefix + ".previous"
+	if err := utils.RemoveFolder(previous); err != nil {
+		return err
+	}
+
+	if err := os.Rename(prefix, previous); err != nil {
+		utils.LogError(err, "php")
+		installer.spinner.AddErrorStatus("Unable to move the existing installation aside")
+		return err
+	}
+
+	installer.previousInstall = previous
+	return nil
+}
+
+// restorePreviousInstall removes the partial installation and puts the
+// existing installation back, restarting PHP-FPM so it is used again
+func (installer *PhpInstaller) restorePreviousInstall() error {
+	prefix := installer.prefixPath()
+	if err := utils.RemoveFolder(prefix); err != nil {
+		return err
+	}
+
+	if installer.previousInstall == "" {
+		return nil
+	}
+
+	if err := os.Rename(installer.previousInstall, prefix); err != nil {
+		return err
+	}
+
+	installer.previousInstall = ""
+
+	if installer.update {
+		utils.SystemdStopService(installer.serviceName())
+		return utils.SystemdStartService(installer.serviceName())
+	}
+
+	return nil
+}
+
+// discardPreviousInstall removes the existing installation once the new
+// one is complete
+func (installer *PhpInstaller) discardPreviousInstall() error {
+	if installer.previousInstall == "" {
+		return nil
+	}
+
+	if err := utils.RemoveFolder(installer.previousInstall); err != nil {
+		utils.LogError(err, "php")
+	}
+
+	installer.previousInstall = ""
+	return nil
+}
+
+// removeSource deletes the extracted PHP source
+func (installer *PhpInstaller) removeSource() error {
+	return utils.RemoveFolder(installer.info.SourcePath)
+}
+
+// saveToBuildCache stores a freshly compiled build so that rebuilding or
+// reinstalling the same version and extensions skips compilation
+func (installer *PhpInstaller) saveToBuildCache() error {
+	if !installer.useBuildCache || installer.fromBuildCache {
+		return nil
+	}
+
+	installer.spinner.UpdatePhrase("Saving Build Cache...")
+
+	name := buildCacheName(installer.info.Version, installer.extensions, installer.depManager.GetDistro())
+	if err := storeCachedBuild(name, installer.version); err != nil {
+		utils.LogError(err, "buildcache")
+		installer.spinner.AddWarningStatus("Unable to save the build to the cache")
+		return nil
+	}
+
+	installer.spinner.AddInfoStatus("Saved build to the cache")
+	return nil
+}
+
 func (installer *PhpInstaller) downloadPhp() error {
 	installer.spinner.UpdatePhrase("Downloading Source from php.net...")
 
 	userCtx, err := utils.GetRealUser()
 	if err != nil {
 		utils.LogError(err, "extract")
-		installer.spinner.StopWithError("Unable to get user information")
+		installer.spinner.AddErrorStatus("Unable to get user information")
 		return fmt.Errorf("error getting user information")
 	}
 
-	if err := utils.DownloadFile(installer.info.DownloadURL, installer.info.ArchivePath, nil); err != nil {
+	if err := utils.DownloadSource(installer.info.DownloadURL, installer.info.ArchivePath); err != nil {
 		utils.LogError(err, "download")
-		installer.spinner.StopWithError("Unable to download")
-		PrintVersionFetchError(installer.version)
+		installer.spinner.AddErrorStatus("Unable to download")
+		installer.downloadFailed = true
 		return fmt.Errorf("unable to download php%s", installer.info.MajorMinor)
 	}
 
+	signed, err := utils.VerifyDownload(installer.info.ArchivePath, installer.info.Checksum, installer.info.DownloadURL+".asc", constants.PhpKeyring)
+	if err != nil {
+		utils.LogError(err, "download")
+		utils.ForgetSource(installer.info.DownloadURL)
+		installer.spinner.AddErrorStatus("PHP source failed verification, the download has been removed")
+		return fmt.Errorf("unable to verify php%s: %w", installer.info.MajorMinor, err)
+	}
+
+	installer.spinner.AddSuccessStatus("Verified SHA-256 Checksum")
+	if signed {
+		installer.spinner.AddSuccessStatus("Verified Signature")
+	}
+
 	if err := utils.ExtractArchive(installer.info.ArchivePath, installer.info.ExtractPath, userCtx); err != nil {
 		utils.LogError(err, "download")
 		os.Remove(installer.info.ArchivePath)
-		installer.spinner.StopWithError("Unable to extract")
-		PrintVersionFetchError(installer.version)
+		installer.spinner.AddErrorStatus("Unable to extract")
+		installer.downloadFailed = true
 		return fmt.Errorf("unable to extract php%s", installer.info.MajorMinor)
 	}
 
+	installer.builtFromSource = true
+
 	tempSourcePath := filepath.Join(installer.info.ExtractPath, fmt.Sprintf("php-%s", installer.info.Version))
 	utils.ReplaceDirectory(installer.info.SourcePath)
 	utils.CopyRecursive(tempSourcePath, installer.info.SourcePath)
@@ -225,11 +442,12 @@ func (installer *PhpInstaller) getVersionInfo() error {
 
 		info, err := getPhpVersionInfo(installer.version, installer.useCache)
 		if err != nil {
-			installer.spinner.StopWithError("Unable to fetch latest version from php.net")
+			installer.spinner.AddErrorStatus("Unable to fetch latest version from php.net")
 			return err
 		}
 
 		installer.info = info
+		installer.info.ConfigureFlags = getConfigureFlags(installer.version, installer.extensions)
 
 		installer.spinner.AddSuccessStatus("Fetched Latest Version")
 		installer.spinner.AddInfoStatus("Version: %s", installer.info.Version)
@@ -238,9 +456,9 @@ func (installer *PhpInstaller) getVersionInfo() error {
 	}
 
 	installer.spinner.UpdatePhrase(fmt.Sprintf("Fetching Version %s...", installer.exactVersion))
-	version, download, err := FetchSpecificVersion(installer.exactVersion)
+	version, download, checksum, err := FetchSpecificVersion(installer.exactVersion)
 	if err != nil {
-		installer.spinner.StopWithError(
+		installer.spinner.AddErrorStatus(
 			fmt.Sprintf("Unable to fetch PHP version %s from php.net", installer.exactVersion),
 		)
 		return err
@@ -252,6 +470,7 @@ func (installer *PhpInstaller) getVersionInfo() error {
 		MajorMinor:     installer.version,
 		Version:        version,
 		DownloadURL:    download,
+		Checksum:       checksum,
 		ConfigureFlags: getConfigureFlags(installer.version, installer.extensions),
 		SourcePackage:  fmt.Sprintf("php-%s", installer.version),
 		ArchivePath:    filepath.Join(tempDir, fmt.Sprintf("php-%s.tar.gz", version)),
@@ -266,54 +485,39 @@ func (installer *PhpInstaller) getVersionInfo() error {
 }
 
 func (installer *PhpInstaller) conflictingBinaries() error {
-	if isYerdManaged("php") {
+	if utils.IsConflictingCommand("php") {
 		installer.spinner.AddWarningStatus("Conflicting root 'php' installation")
 	} else {
 		installer.spinner.AddInfoStatus("No conflicting 'php' installations")
 	}
 
-	if isYerdManaged(fmt.Sprintf("php%s", installer.version)) {
+	if utils.IsConflictingCommand(fmt.Sprintf("php%s", installer.version)) {
 		installer.spinner.AddErrorStatus("Conflicting 'php%s' installation", installer.version)
-		installer.spinner.StopWithError("Unable to installed php%s due to conflicting executable", installer.version)
+		installer.spinner.AddErrorStatus("Unable to installed php%s due to conflicting executable", installer.version)
 		return fmt.Errorf("conflicting php executable")
 	}
 
 	return nil
 }
 
-func isYerdManaged(command string) bool {
-	if path, exists := utils.CommandExists(command); exists {
-		if utils.IsSymlink(path) {
-			rootPath, err := utils.ReadSymlink(path)
-			utils.LogInfo("php", "SymLink for '%s' at '%s' is '%s'", command, path, rootPath)
-			return err != nil || !strings.HasPrefix(rootPath, constants.YerdBaseDir)
-		} else {
-			utils.LogInfo("php", "'%s' at '%s' is not a symlink", command, path)
-			return !strings.HasPrefix(path, constants.YerdBaseDir)
-		}
-	}
-
-	return false
-}
-
 func (installer *PhpInstaller) configurePhp() error {
 	utils.LogInfo("php", "Starting to configure PHP")
 	installer.spinner.UpdatePhrase("Configuring PHP...")
 
 	configurePath := filepath.Join(installer.info.SourcePath, "configure")
 	if exists := utils.FileExists(configurePath); !exists {
-		installer.spinner.StopWithError("Configure script not found")
+		installer.spinner.AddErrorStatus("Configure script not found")
 		return fmt.Errorf("configure script not found")
 	}
 
 	if err := utils.Chmod(configurePath, 0755); err != nil {
-		installer.spinner.StopWithError("Unable to make configure script executable")
+		installer.spinner.AddErrorStatus("Unable to make configure script executable")
 		return fmt.Errorf("unable to make configure executable")
 	}
 
 	args := append([]string{"/bin/bash", configurePath}, installer.info.ConfigureFlags...)
 	if _, success := utils.ExecuteCommandInDirAsUser(installer.info.SourcePath, args[0], args[1:]...); !success {
-		installer.spinner.StopWithError("Unable to run configure script")
+		installer.spinner.AddErrorStatus("Unable to run configure script")
 		return fmt.Errorf("unable to run configure script")
 	}
 
@@ -329,7 +533,7 @@ func (installer *PhpInstaller) compilePhp() error {
 
 	nproc := utils.GetProcessorCount()
 	if _, success := utils.ExecuteCommandInDirAsUser(installer.info.SourcePath, "make", fmt.Sprintf("-j%d", nproc)); !success {
-		installer.spinner.StopWithError("Unable to compile PHP")
+		installer.spinner.AddErrorStatus("Unable to compile PHP")
 		return fmt.Errorf("unable to compile php")
 	}
 
@@ -345,12 +549,12 @@ func (installer *PhpInstaller) makePhp() error {
 
 	output, success := utils.ExecuteCommandInDir(installer.info.SourcePath, "make", "install")
 	if !success {
-		installer.spinner.StopWithError("Failed to install PHP")
+		installer.spinner.AddErrorStatus("Failed to install PHP")
 		utils.LogDebug("install", "%s", output)
 		return fmt.Errorf("unable to install php")
 	}
 
-	installer.installPath = fmt.Sprintf("%s/php%s", constants.YerdPHPDir, installer.version)
+	installer.installPath = installer.prefixPath()
 
 	utils.RemoveFolder(installer.info.SourcePath)
 
@@ -366,14 +570,22 @@ func (installer *PhpInstaller) createSymlinks() error {
 	localBinaryPath := constants.YerdBinDir + "/php" + installer.version
 	globalBinaryPath := constants.SystemBinDir + "/php" + installer.version
 
+	if !utils.IsSymlink(localBinaryPath) {
+		installer.createdSymlinks = append(installer.createdSymlinks, localBinaryPath)
+	}
+
 	if err := utils.CreateSymlink(installedBinary, localBinaryPath); err != nil {
-		installer.spinner.StopWithError("Unable to create local Symlink")
+		installer.spinner.AddErrorStatus("Unable to create local Symlink")
 		utils.LogError(err, "symlink")
 		return err
 	}
 
+	if !utils.IsSymlink(globalBinaryPath) {
+		installer.createdSymlinks = append(installer.createdSymlinks, globalBinaryPath)
+	}
+
 	if err := utils.CreateSymlink(localBinaryPath, globalBinaryPath); err != nil {
-		installer.spinner.StopWithError("Unable to create global Symlink")
+		installer.spinner.AddErrorStatus("Unable to create global Symlink")
 		utils.LogError(err, "symlink")
 		return err
 	}
@@ -385,6 +597,21 @@ func (installer *PhpInstaller) createSymlinks() error {
 	return nil
 }
 
+// removeCreatedSymlinks removes the symlinks which did not exist before
+// the installation started
+func (installer *PhpInstaller) removeCreatedSymlinks() error {
+	for _, link := range installer.createdSymlinks {
+		if utils.IsSymlink(link) {
+			if err := os.Remove(link); err != nil {
+				return err
+			}
+		}
+	}
+
+	installer.createdSymlinks = nil
+	return nil
+}
+
 func (installer *PhpInstaller) verifyInstall() error {
 	installer.spinner.UpdatePhrase("Verifying Installation")
 
@@ -398,7 +625,7 @@ func (installer *PhpInstaller) verifyInstall() error {
 	for _, binary := range binaries {
 		utils.LogInfo("verify", "Testing: %s", binary)
 		if _, success := utils.ExecuteCommandAsUser(binary, "-v"); !success {
-			installer.spinner.StopWithError("PHP binary not executable: %s", binary)
+			installer.spinner.AddErrorStatus("PHP binary not executable: %s", binary)
 			return fmt.Errorf("binary not executable")
 		}
 
@@ -418,6 +645,7 @@ func (installer *PhpInstaller) createDefaultConfig() error {
 	fpmPoolConf := filepath.Join(configDir, constants.FPMPoolDir, constants.FPMPoolConfig)
 	phpFpmConf := filepath.Join(configDir, "php-fpm.conf")
 
+	installer.createdConfig = !utils.IsDirectory(configDir)
 	utils.CreateDirectory(filepath.Join(configDir, constants.FPMPoolDir))
 	utils.CreateDirectory(filepath.Join(constants.YerdPHPDir, "logs"))
 	utils.CreateDirectory(constants.FPMSockDir)
@@ -428,18 +656,19 @@ func (installer *PhpInstaller) createDefaultConfig() error {
 	updateFpmPoolConf := installer.shouldReplaceConfig(fpmPoolConf)
 
 	if updateIni {
-		if err := installer.downloadAndReplace("php", "php.ini", iniPath, utils.TemplateData{}); err != nil {
+		if err := installer.writeTemplate("php", "php.ini", iniPath, utils.TemplateData{}); err != nil {
 			return err
 		}
 	}
 
 	if updatePhpFpmConf {
 		data := utils.TemplateData{
+			"version":  installer.version,
 			"pid_path": filepath.Join(constants.FPMSockDir, fmt.Sprintf("php%s-fpm.pid", installer.version)),
 			"log_path": filepath.Join(constants.FPMLogDir, fmt.Sprintf("php%s-fpm.log", installer.version)),
 			"pool_dir": filepath.Join(constants.YerdEtcDir, "php"+installer.version, constants.FPMPoolDir),
 		}
-		if err := installer.downloadAndReplace("php", "php-fpm.conf", phpFpmConf, data); err != nil {
+		if err := installer.writeTemplate("php", "php-fpm.conf", phpFpmConf, data); err != nil {
 			return err
 		}
 	}
@@ -449,10 +678,10 @@ func (installer *PhpInstaller) createDefaultConfig() error {
 			"version":   installer.version,
 			"sock_path": filepath.Join(constants.FPMSockDir, fmt.Sprintf("php%s-fpm.sock", installer.version)),
 			"log_path":  filepath.Join(constants.FPMLogDir, fmt.Sprintf("php%s-fpm.log", installer.version)),
-			"user":      GetFPMUser(),
-			"group":     GetFPMGroup(),
+			"user":      utils.GetFPMUser(),
+			"group":     utils.GetFPMGroup(),
 		}
-		if err := installer.downloadAndReplace("php", "www.conf", fpmPoolConf, data); err != nil {
+		if err := installer.writeTemplate("php", "www.conf", fpmPoolConf, data); err != nil {
 			return err
 		}
 	}
@@ -462,10 +691,42 @@ func (installer *PhpInstaller) createDefaultConfig() error {
 	return nil
 }
 
+// restoreDefaultConfig puts back the configuration files replaced by
+// createDefaultConfig, removing the configuration folder if it was new
+func (installer *PhpInstaller) restoreDefaultConfig() error {
+	configDir := filepath.Join(constants.YerdEtcDir, "php"+installer.version)
+	if installer.createdConfig {
+		return utils.RemoveFolder(configDir)
+	}
+
+	return installer.restoreReplacedFiles(configDir)
+}
+
+// systemdPath returns the location of the PHP-FPM systemd unit
+func (installer *PhpInstaller) systemdPath() string {
+	return filepath.Join(constants.SystemdDir, installer.serviceName()+".service")
+}
+
+// removeSystemdService stops a newly installed PHP-FPM service and puts
+// back the unit it replaced, a rebuilt version is restarted by
+// restorePreviousInstall once its previous installation is back
+func (installer *PhpInstaller) removeSystemdService() error {
+	if !installer.update {
+		utils.SystemdStopService(installer.serviceName())
+		utils.SystemdDisable(installer.serviceName())
+	}
+
+	if err := installer.restoreReplacedFiles(installer.systemdPath()); err != nil {
+		return err
+	}
+
+	return utils.SystemdReload()
+}
+
 func (installer *PhpInstaller) setupSystemdService() error {
 	installer.spinner.UpdatePhrase("Configuring Systemd")
 
-	systemdPath := filepath.Join(constants.SystemdDir, fmt.Sprintf("yerd-php%s-fpm.service", installer.version))
+	systemdPath := installer.systemdPath()
 	updateSystemdConf := installer.shouldReplaceConfig(systemdPath)
 
 	if updateSystemdConf {
@@ -479,7 +740,7 @@ func (installer *PhpInstaller) setupSystemdService() error {
 			"main_config_path": phpFpmConf,
 		}
 
-		if err := installer.downloadAndReplace("php", "systemd.conf", systemdPath, data); err != nil {
+		if err := installer.writeTemplate("php", "systemd.conf", systemdPath, data); err != nil {
 			return err
 		}
 
@@ -493,7 +754,7 @@ func (installer *PhpInstaller) setupSystemdService() error {
 		installer.spinner.AddInfoStatus("[Systemd] Reloaded daemons")
 	}
 
-	serviceName := fmt.Sprintf("yerd-php%s-fpm", installer.version)
+	serviceName := installer.serviceName()
 	utils.SystemdStopService(serviceName)
 	if err := utils.SystemdStartService(serviceName); err != nil {
 		utils.LogInfo("setupSystemd", "Unable to start service %s", serviceName)
@@ -518,23 +779,68 @@ func (installer *PhpInstaller) shouldReplaceConfig(path string) bool {
 	return true
 }
 
-func (installer *PhpInstaller) downloadAndReplace(folder, file, path string, data utils.TemplateData) error {
-	content, err := utils.FetchFromGitHub(folder, file)
+// writeTemplate renders a template to path, keeping the file it
+// replaces so restoreReplacedFiles can put it back
+func (installer *PhpInstaller) writeTemplate(folder, file, path string, data utils.TemplateData) error {
+	if installer.replacedFiles == nil {
+		installer.replacedFiles = make(map[string][]byte)
+	}
+
+	if _, recorded := installer.replacedFiles[path]; !recorded {
+		previous, err := os.ReadFile(path)
+		if err != nil {
+			previous = nil
+		}
+		installer.replacedFiles[path] = previous
+	}
+
+	content, err := utils.LoadTemplate(folder, file)
 	if err != nil {
-		installer.spinner.StopWithError("Failed to download %s", file)
+		installer.spinner.AddErrorStatus("Failed to load the %s template", file)
+		return err
+	}
+
+	fullContent, err := utils.Template(content, data)
+	if err != nil {
+		utils.LogError(err, "template")
+		installer.spinner.AddErrorStatus("Failed to render %s: %v", file, err)
 		return err
 	}
 
-	fullContent := utils.Template(content, data)
 	if err := utils.WriteStringToFile(path, fullContent, constants.FilePermissions); err != nil {
 		utils.LogError(err, "dl")
-		installer.spinner.StopWithError("Failed to write to %s", file)
+		installer.spinner.AddErrorStatus("Failed to write to %s", file)
 		return err
 	}
 
 	return nil
 }
 
+// restoreReplacedFiles puts back the files written by writeTemplate
+// within path, removing those which did not exist beforehand
+func (installer *PhpInstaller) restoreReplacedFiles(path string) error {
+	for file, previous := range installer.replacedFiles {
+		if file != path && !strings.HasPrefix(file, path+string(filepath.Separator)) {
+			continue
+		}
+
+		var err error
+		if previous == nil {
+			err = utils.RemoveFile(file)
+		} else {
+			err = utils.WriteToFile(file, previous, constants.FilePermissions)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		delete(installer.replacedFiles, file)
+	}
+
+	return nil
+}
+
 func (installer *PhpInstaller) writeConfig() error {
 	var existing *config.PhpInfo
 	configPath := fmt.Sprintf("php.[%s]", installer.version)
@@ -569,6 +875,12 @@ func (installer *PhpInstaller) UseVersion(fullVersion string) {
 	installer.exactVersion = fullVersion
 }
 
+// UseExtensions replaces the extensions PHP is built with, by default
+// these come from the configuration or the default extension set
+func (installer *PhpInstaller) UseExtensions(extensions []string) {
+	installer.extensions = extensions
+}
+
 // getConfigureFlags gets the default configure flags and appends the extension
 // configure flags required to build a specific version of PHP
 // majorMinor: PHP version, for example 8.1
@@ -579,15 +891,16 @@ func getConfigureFlags(majorMinor string, extensions []string) []string {
 		fmt.Sprintf("--with-config-file-path=%s/php%s", constants.YerdEtcDir, majorMinor),
 		fmt.Sprintf("--with-config-file-scan-dir=%s/php%s/conf.d", constants.YerdEtcDir, majorMinor),
 		"--enable-fpm",
-		fmt.Sprintf("--with-fpm-user=%s", GetFPMUser()),
-		fmt.Sprintf("--with-fpm-group=%s", GetFPMGroup()),
+		fmt.Sprintf("--with-fpm-user=%s", utils.GetFPMUser()),
+		fmt.Sprintf("--with-fpm-group=%s", utils.GetFPMGroup()),
 		"--enable-cli",
 		"--with-pear",
 	}
 
-	extensionFlags := constants.GetExtensionConfigureFlags(extensions)
+	extensionFlags := constants.GetExtensionConfigureFlags(majorMinor, extensions)
+	versionFlags := constants.GetPhpProfile(majorMinor).ExtraFlags
 
-	return append(baseFlags, extensionFlags...)
+	return slices.Concat(baseFlags, versionFlags, extensionFlags)
 }
 
 // printVersionFetchError displays helpful error message when version fetching fails.
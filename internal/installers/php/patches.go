@@ -0,0 +1,151 @@
+package php
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// sourcePatch is a patch applied to the PHP source before it is configured
+type sourcePatch struct {
+	Name    string
+	Content string
+}
+
+// patchFolder is the template folder holding the patches for a PHP
+// version, eg: php/patches/7.4
+func patchFolder(version string) string {
+	return path.Join("php", "patches", version)
+}
+
+// loadPatches returns the .patch files for a PHP version, bundled with
+// YERD or placed in /opt/yerd/etc/templates/php/patches/{version}
+func loadPatches(version string) ([]sourcePatch, error) {
+	patches := []sourcePatch{}
+
+	for _, name := range utils.ListTemplates(patchFolder(version)) {
+		if !strings.HasSuffix(name, ".patch") {
+			continue
+		}
+
+		content, err := utils.LoadTemplate(patchFolder(version), name)
+		if err != nil {
+			return nil, err
+		}
+
+		patches = append(patches, sourcePatch{Name: name, Content: content})
+	}
+
+	return patches, nil
+}
+
+// patchesDigest identifies the patches applied to a build, so builds
+// with different patches are cached separately
+func patchesDigest(version string) string {
+	patches, err := loadPatches(version)
+	if err != nil || len(patches) == 0 {
+		return ""
+	}
+
+	hash := sha256.New()
+	for _, patch := range patches {
+		fmt.Fprintf(hash, "%s\n%s\n", patch.Name, patch.Content)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// MissingPatches returns the extensions which only build on version
+// with a source patch, when none of the extensions has one available
+// extensions: The extensions PHP would be built with
+func MissingPatches(version string, extensions []string) ([]string, error) {
+	patched := []string{}
+	for _, extension := range constants.GetPhpProfile(version).PatchedExtensions {
+		if slices.Contains(extensions, extension) {
+			patched = append(patched, extension)
+		}
+	}
+
+	if len(patched) == 0 {
+		return patched, nil
+	}
+
+	patches, err := loadPatches(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(patches) > 0 {
+		return []string{}, nil
+	}
+
+	return patched, nil
+}
+
+// PatchDirectory returns the directory patches for version are read from
+func PatchDirectory(version string) string {
+	return filepath.Join(constants.TemplatesDir, filepath.FromSlash(patchFolder(version)))
+}
+
+// checkPatches stops the install before anything is downloaded when an
+// extension which only builds with a source patch has none available
+func (installer *PhpInstaller) checkPatches() error {
+	patched, err := MissingPatches(installer.version, installer.extensions)
+	if err != nil {
+		installer.spinner.AddErrorStatus("Unable to load the patches for PHP %s", installer.version)
+		return err
+	}
+
+	if len(patched) == 0 {
+		return nil
+	}
+
+	installer.spinner.AddErrorStatus("PHP %s needs a source patch to build %s", installer.version, strings.Join(patched, ", "))
+	installer.spinner.AddInfoStatus("- Add the patch to %s", PatchDirectory(installer.version))
+	if installer.update {
+		installer.spinner.AddInfoStatus("- Or remove it with 'yerd php %s extensions remove %s'", installer.version, strings.Join(patched, " "))
+	}
+
+	return fmt.Errorf("no patch for the %s extension of php%s", strings.Join(patched, ", "), installer.version)
+}
+
+// applyPatches applies the patches for the version to the extracted
+// source, in name order
+func (installer *PhpInstaller) applyPatches() error {
+	patches, err := loadPatches(installer.version)
+	if err != nil {
+		installer.spinner.AddErrorStatus("Unable to load the patches for PHP %s", installer.version)
+		return err
+	}
+
+	for _, patch := range patches {
+		installer.spinner.UpdatePhrase(fmt.Sprintf("Applying %s...", patch.Name))
+
+		patchPath := filepath.Join(os.TempDir(), fmt.Sprintf("yerd-php%s-%s", installer.version, patch.Name))
+		if err := utils.WriteStringToFile(patchPath, patch.Content, 0644); err != nil {
+			installer.spinner.AddErrorStatus("Unable to write %s", patch.Name)
+			return err
+		}
+
+		output, success := utils.ExecuteCommandInDirAsUser(installer.info.SourcePath, "patch", "-p1", "-i", patchPath)
+		os.Remove(patchPath)
+
+		if !success {
+			utils.LogDebug("patch", "%s", output)
+			installer.spinner.AddErrorStatus("Unable to apply %s", patch.Name)
+			return fmt.Errorf("unable to apply %s to php%s", patch.Name, installer.version)
+		}
+
+		installer.spinner.AddSuccessStatus("Applied %s", patch.Name)
+	}
+
+	return nil
+}
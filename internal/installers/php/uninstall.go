@@ -35,7 +35,7 @@ func UninstallPhp(info *config.PhpInfo) error {
 		func() error { return utils.RemoveFolder(installDir) },
 		func() error { return utils.RemoveFolder(etcDir) },
 		func() error {
-			if info.IsCLI {
+			if info.IsCLI && !config.GetShimConfig().Enabled {
 				return utils.RemoveSymlink(constants.GlobalPhpPath)
 			}
 			return nil
@@ -2,13 +2,14 @@ package php
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/lumosolutions/yerd/internal/config"
 	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
 )
 
 type PHPReleaseResponse struct {
@@ -30,12 +31,14 @@ type VersionCache struct {
 	LastUpdated    time.Time         `json:"last_updated"`
 	LatestVersions map[string]string `json:"latest_versions"`
 	DownloadURLs   map[string]string `json:"download_urls"`
+	Checksums      map[string]string `json:"checksums"`
 }
 
 type PhpVersionInfo struct {
 	MajorMinor     string
 	Version        string
 	DownloadURL    string
+	Checksum       string
 	SourcePackage  string
 	ConfigureFlags []string
 	ArchivePath    string
@@ -44,103 +47,102 @@ type PhpVersionInfo struct {
 }
 
 const (
-	PHPReleasesURL     = "https://www.php.net/releases/index.php?json&version="
+	PHPReleasesURL     = utils.PhpSourceBase + "/releases/index.php?json&version="
 	CacheValidDuration = 1 * time.Hour
-	HTTPTimeout        = 10 * time.Second
 	CacheFileExtension = "/version_cache.json"
 	TempFileExtension  = ".tmp"
 )
 
-func FetchSpecificVersion(version string) (string, string, error) {
-	return fetchLatestForMajorMinor(version)
+// ErrUnreleasedVersion is returned when php.net has no release of a version
+var ErrUnreleasedVersion = errors.New("version has not been released")
+
+// FetchSpecificVersion returns the version, download URL and SHA-256
+// checksum of a PHP release, version may be a full version, eg: 8.3.12,
+// or a pre-release from the catalogue, eg: 8.5.0RC2
+func FetchSpecificVersion(version string) (string, string, string, error) {
+	latest, downloadURL, checksum, err := fetchLatestForMajorMinor(version)
+	if !errors.Is(err, ErrUnreleasedVersion) {
+		return latest, downloadURL, checksum, err
+	}
+
+	catalogue, _ := RefreshCatalogue(false)
+	if prerelease, found := catalogue.FindPrerelease(version); found {
+		return prerelease.Version, prerelease.DownloadURL, prerelease.Checksum, nil
+	}
+
+	return "", "", "", err
 }
 
-// FetchLatestVersions retrieves latest PHP version information from php.net for all supported versions.
+// FetchLatestVersions retrieves latest PHP version information from php.net for every version in
+// the catalogue, release lines without a stable release use their newest pre-release.
 // Returns latest versions map, download URLs map, or error if any version fetch fails.
 func FetchLatestVersions() (map[string]string, map[string]string, error) {
-	supportedMajorMinor := constants.GetAvailablePhpVersions()
+	catalogue, err := RefreshCatalogue(true)
+	if err != nil {
+		utils.LogWarning("versions", "Unable to refresh the catalogue: %v", err)
+	}
+
 	latestVersions := make(map[string]string)
 	downloadURLs := make(map[string]string)
+	checksums := make(map[string]string)
+
+	for _, majorMinor := range constants.GetAvailablePhpVersions() {
+		latest, downloadURL, checksum, err := fetchLatestForMajorMinor(majorMinor)
+		if errors.Is(err, ErrUnreleasedVersion) {
+			prerelease, found := catalogue.Prereleases[majorMinor]
+			if !found {
+				continue
+			}
+
+			latest, downloadURL, checksum, err = prerelease.Version, prerelease.DownloadURL, prerelease.Checksum, nil
+		}
 
-	for _, majorMinor := range supportedMajorMinor {
-		latest, downloadURL, err := fetchLatestForMajorMinor(majorMinor)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to fetch latest version for %s: %v", majorMinor, err)
 		}
 		latestVersions[majorMinor] = latest
 		downloadURLs[latest] = downloadURL
+		checksums[latest] = checksum
+	}
+
+	if err := SaveVersionCache(latestVersions, downloadURLs, checksums); err != nil {
+		fmt.Printf("Warning: failed to save version cache: %v\n", err)
 	}
 
 	return latestVersions, downloadURLs, nil
 }
 
-// fetchLatestForMajorMinor gets the latest version and download URL for a specific PHP major.minor version.
-// majorMinor: Version string like "8.3". Returns version string, download URL, or error if fetch fails.
-func fetchLatestForMajorMinor(majorMinor string) (string, string, error) {
-	url := PHPReleasesURL + majorMinor
-
-	client := &http.Client{Timeout: HTTPTimeout}
-	resp, err := client.Get(url)
+// fetchLatestForMajorMinor gets the latest version, download URL and SHA-256 for a specific PHP major.minor version.
+// majorMinor: Version string like "8.3". Returns version string, download URL, checksum, or error if fetch fails.
+func fetchLatestForMajorMinor(majorMinor string) (string, string, string, error) {
+	content, err := utils.FetchSource(PHPReleasesURL + majorMinor)
 	if err != nil {
-		return "", "", fmt.Errorf("HTTP request failed: %v", err)
+		return "", "", "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return "", "", fmt.Errorf("HTTP request failed with status %d", resp.StatusCode)
+	var release PHPReleaseResponse
+	if err := json.Unmarshal(content, &release); err != nil {
+		return "", "", "", fmt.Errorf("JSON decode failed: %v", err)
 	}
 
-	var release PHPReleaseResponse
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return "", "", fmt.Errorf("JSON decode failed: %v", err)
+	if release.Version == "" {
+		return "", "", "", fmt.Errorf("%w: %s", ErrUnreleasedVersion, majorMinor)
 	}
 
-	downloadURL := ""
 	for _, source := range release.Source {
 		if strings.HasSuffix(source.Filename, ".tar.gz") {
-			downloadURL = fmt.Sprintf("https://www.php.net/distributions/%s", source.Filename)
-			break
-		}
-	}
+			if source.SHA256 == "" {
+				return "", "", "", fmt.Errorf("no sha256 published for %s", source.Filename)
+			}
 
-	if downloadURL == "" {
-		return "", "", fmt.Errorf("no tar.gz download found for %s", majorMinor)
+			downloadURL := fmt.Sprintf("%s/distributions/%s", utils.PhpSourceBase, source.Filename)
+			return release.Version, downloadURL, source.SHA256, nil
+		}
 	}
 
-	return release.Version, downloadURL, nil
+	return "", "", "", fmt.Errorf("no tar.gz download found for %s", majorMinor)
 }
 
-// compareVersions compares two semantic version strings numerically.
-// v1, v2: Version strings to compare. Returns 1 if v1>v2, -1 if v1<v2, 0 if equal.
-// func compareVersions(v1, v2 string) int {
-// 	parts1 := strings.Split(v1, ".")
-// 	parts2 := strings.Split(v2, ".")
-
-// 	maxLen := len(parts1)
-// 	if len(parts2) > maxLen {
-// 		maxLen = len(parts2)
-// 	}
-
-// 	for i := 0; i < maxLen; i++ {
-// 		var n1, n2 int
-
-// 		if i < len(parts1) {
-// 			n1, _ = strconv.Atoi(parts1[i])
-// 		}
-// 		if i < len(parts2) {
-// 			n2, _ = strconv.Atoi(parts2[i])
-// 		}
-
-// 		if n1 > n2 {
-// 			return 1
-// 		} else if n1 < n2 {
-// 			return -1
-// 		}
-// 	}
-
-// 	return 0
-// }
-
 // GetCachedVersions retrieves version information from local cache if valid.
 // Returns cached version data and validity flag, or nil and false if cache invalid/missing.
 func GetCachedVersions() (*VersionCache, bool) {
@@ -150,20 +152,21 @@ func GetCachedVersions() (*VersionCache, bool) {
 		return nil, false
 	}
 
-	if time.Since(cache.LastUpdated) > CacheValidDuration {
+	if time.Since(cache.LastUpdated) > CacheValidDuration || cache.Checksums == nil {
 		return nil, false
 	}
 
 	return &cache, true
 }
 
-// SaveVersionCache writes version and download URL data to local cache file with proper ownership.
-// latestVersions: Version mapping, downloadURLs: Download URL mapping. Returns error if save fails.
-func SaveVersionCache(latestVersions, downloadURLs map[string]string) error {
+// SaveVersionCache writes version, download URL and checksum data to local cache file with proper ownership.
+// latestVersions: Version mapping, downloadURLs: Download URL mapping, checksums: SHA-256 by version. Returns error if save fails.
+func SaveVersionCache(latestVersions, downloadURLs, checksums map[string]string) error {
 	cache := VersionCache{
 		LastUpdated:    time.Now(),
 		LatestVersions: latestVersions,
 		DownloadURLs:   downloadURLs,
+		Checksums:      checksums,
 	}
 
 	if err := config.SetStruct("versions.php.cache", cache); err != nil {
@@ -176,102 +179,15 @@ func SaveVersionCache(latestVersions, downloadURLs map[string]string) error {
 // GetLatestVersions retrieves PHP versions from cache or fetches fresh data if cache expired.
 // Returns latest versions map, download URLs map, or error if fetch fails.
 func GetLatestVersions() (map[string]string, map[string]string, error) {
-
 	if cache, valid := GetCachedVersions(); valid {
 		return cache.LatestVersions, cache.DownloadURLs, nil
 	}
 
-	latestVersions, downloadURLs, err := FetchLatestVersions()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if err := SaveVersionCache(latestVersions, downloadURLs); err != nil {
-
-		fmt.Printf("Warning: failed to save version cache: %v\n", err)
-	}
-
-	return latestVersions, downloadURLs, nil
+	return FetchLatestVersions()
 }
 
 // GetLatestVersionsFresh bypasses cache and fetches fresh PHP version data from php.net.
 // Returns latest versions map, download URLs map, or error if fetch fails.
 func GetLatestVersionsFresh() (map[string]string, map[string]string, error) {
-
-	latestVersions, downloadURLs, err := FetchLatestVersions()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if err := SaveVersionCache(latestVersions, downloadURLs); err != nil {
-
-		fmt.Printf("Warning: failed to save version cache: %v\n", err)
-	}
-
-	return latestVersions, downloadURLs, nil
+	return FetchLatestVersions()
 }
-
-// CheckForUpdates compares installed PHP versions against latest available versions using cache.
-// installedVersions: Map of installed version info. Returns update availability map or error.
-// func CheckForUpdates(installedVersions map[string]string) (map[string]bool, error) {
-// 	latestVersions, _, err := GetLatestVersions()
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	updates := make(map[string]bool)
-
-// 	for majorMinor, installedVersion := range installedVersions {
-// 		if latestVersion, exists := latestVersions[majorMinor]; exists {
-
-// 			installedFull := extractVersionFromString(installedVersion)
-// 			updates[majorMinor] = compareVersions(latestVersion, installedFull) > 0
-// 		}
-// 	}
-
-// 	return updates, nil
-// }
-
-// CheckForUpdatesFresh compares installed versions against fresh data and returns available updates.
-// installedVersions: Map of installed version info. Returns update flags, available updates map, or error.
-// func CheckForUpdatesFresh(installedVersions map[string]string) (map[string]bool, map[string]string, error) {
-// 	latestVersions, _, err := GetLatestVersionsFresh()
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-
-// 	updates := make(map[string]bool)
-// 	availableUpdates := make(map[string]string)
-
-// 	for majorMinor, installedVersion := range installedVersions {
-// 		if latestVersion, exists := latestVersions[majorMinor]; exists {
-
-// 			installedFull := extractVersionFromString(installedVersion)
-// 			hasUpdate := compareVersions(latestVersion, installedFull) > 0
-// 			updates[majorMinor] = hasUpdate
-// 			if hasUpdate {
-// 				availableUpdates[majorMinor] = latestVersion
-// 			}
-// 		}
-// 	}
-
-// 	return updates, availableUpdates, nil
-// }
-
-// extractVersionFromString extracts semantic version number from version string using regex.
-// versionStr: Input version string. Returns extracted version or original string if no match.
-// func extractVersionFromString(versionStr string) string {
-
-// 	versionRegex := regexp.MustCompile(`\d+\.\d+\.\d+`)
-// 	matches := versionRegex.FindString(versionStr)
-// 	if matches != "" {
-// 		return matches
-// 	}
-// 	return versionStr
-// }
-
-// ExtractVersionFromString is a public wrapper for extractVersionFromString.
-// versionStr: Input version string. Returns extracted semantic version number.
-// func ExtractVersionFromString(versionStr string) string {
-// 	return extractVersionFromString(versionStr)
-// }
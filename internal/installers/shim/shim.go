@@ -0,0 +1,321 @@
+package shim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+const (
+	PhpVersionFile    = ".php-version"
+	ProjectConfigFile = ".yerd.json"
+	shimMarker        = "# YERD SHIM - DO NOT MODIFY"
+)
+
+// ProjectConfig represents the contents of a .yerd.json file
+type ProjectConfig struct {
+	Php string `json:"php"`
+}
+
+// Resolution describes which PHP version was selected for a
+// directory and where that decision came from
+type Resolution struct {
+	Version string
+	Source  string
+}
+
+var versionPattern = regexp.MustCompile(`(\d+\.\d+)`)
+
+// Resolve walks up from dir looking for a .php-version or .yerd.json
+// file, falling back to the PHP version marked as the CLI version
+// dir: The directory to start searching from
+func Resolve(dir string) (*Resolution, error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if version, found := readVersionFile(filepath.Join(current, PhpVersionFile)); found {
+			return &Resolution{Version: version, Source: filepath.Join(current, PhpVersionFile)}, nil
+		}
+
+		if version, found := readProjectConfig(filepath.Join(current, ProjectConfigFile)); found {
+			return &Resolution{Version: version, Source: filepath.Join(current, ProjectConfigFile)}, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	if version, found := cliVersion(); found {
+		return &Resolution{Version: version, Source: "cli"}, nil
+	}
+
+	return nil, fmt.Errorf("no php version pinned and no default CLI version set")
+}
+
+// readVersionFile reads a .php-version file, accepting values
+// such as 8.3, 8.3.12 or php-8.3 and returning the major.minor
+func readVersionFile(path string) (string, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	return normaliseVersion(string(content))
+}
+
+// readProjectConfig reads the php value from a .yerd.json file
+func readProjectConfig(path string) (string, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	var project ProjectConfig
+	if err := json.Unmarshal(content, &project); err != nil {
+		return "", false
+	}
+
+	return normaliseVersion(project.Php)
+}
+
+// normaliseVersion extracts the major.minor version from a string
+func normaliseVersion(value string) (string, bool) {
+	match := versionPattern.FindString(strings.TrimSpace(value))
+	if match == "" {
+		return "", false
+	}
+
+	return match, true
+}
+
+// cliVersion returns the PHP version currently marked as the CLI version
+func cliVersion() (string, bool) {
+	if !config.Exists("php") {
+		return "", false
+	}
+
+	all := make(config.PhpConfig)
+	if err := config.GetStruct("php", &all); err != nil {
+		return "", false
+	}
+
+	for _, info := range all {
+		if info.IsCLI {
+			return info.Version, true
+		}
+	}
+
+	return "", false
+}
+
+// Exec replaces the current process with the requested tool, using
+// the PHP version resolved for the current working directory
+// args: The tool (php or composer) followed by its arguments
+func Exec(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no tool specified, expected php or composer")
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	resolution, err := Resolve(dir)
+	if err != nil {
+		return err
+	}
+
+	phpBinary := filepath.Join(constants.YerdBinDir, "php"+resolution.Version)
+	if !utils.FileExists(phpBinary) {
+		return fmt.Errorf("php %s (from %s) is not installed, run 'sudo yerd php %s install'", resolution.Version, resolution.Source, resolution.Version)
+	}
+
+	var argv []string
+	switch args[0] {
+	case "php":
+		argv = append([]string{phpBinary}, args[1:]...)
+	case "composer":
+		if !utils.FileExists(constants.LocalComposerPath) {
+			return fmt.Errorf("composer is not installed, run 'sudo yerd composer install'")
+		}
+		argv = append([]string{phpBinary, constants.LocalComposerPath}, args[1:]...)
+	default:
+		return fmt.Errorf("unknown tool '%s', expected php or composer", args[0])
+	}
+
+	return syscall.Exec(phpBinary, argv, withSiteEnvironment(os.Environ(), dir))
+}
+
+// withSiteEnvironment adds the environment variables of the YERD site
+// containing dir to environ, variables already set take precedence
+func withSiteEnvironment(environ []string, dir string) []string {
+	if !config.Exists("web") {
+		return environ
+	}
+
+	var site *config.SiteConfig
+	for _, candidate := range config.GetWebConfig().Sites {
+		if candidate.RootDirectory == "" {
+			continue
+		}
+
+		if dir != candidate.RootDirectory && !strings.HasPrefix(dir, candidate.RootDirectory+string(filepath.Separator)) {
+			continue
+		}
+
+		if site == nil || len(candidate.RootDirectory) > len(site.RootDirectory) {
+			current := candidate
+			site = &current
+		}
+	}
+
+	if site == nil {
+		return environ
+	}
+
+	for key, value := range site.Env {
+		if _, set := os.LookupEnv(key); !set {
+			environ = append(environ, key+"="+value)
+		}
+	}
+
+	return environ
+}
+
+// Enable replaces the global php and composer commands with shims
+// that resolve the PHP version per project
+func Enable() error {
+	binary, err := os.Executable()
+	if err != nil {
+		utils.LogError(err, "shim")
+		return fmt.Errorf("unable to locate the yerd binary")
+	}
+
+	if err := WriteShim(constants.GlobalPhpPath, binary, "php"); err != nil {
+		return err
+	}
+
+	if utils.FileExists(constants.LocalComposerPath) {
+		if err := WriteShim(constants.GlobalComposerPath, binary, "composer"); err != nil {
+			return err
+		}
+	}
+
+	return config.SetStruct("shims", &config.ShimConfig{
+		Enabled: true,
+		Binary:  binary,
+	})
+}
+
+// Disable removes the php and composer shims and restores the
+// symlinks to the CLI version of PHP and the YERD managed composer
+func Disable() error {
+	for _, path := range []string{constants.GlobalPhpPath, constants.GlobalComposerPath} {
+		if IsShim(path) {
+			if err := utils.RemoveFile(path); err != nil {
+				utils.LogError(err, "shim")
+				return err
+			}
+		}
+	}
+
+	if version, found := cliVersion(); found {
+		binaryPath := filepath.Join(constants.YerdBinDir, "php"+version)
+		if err := utils.CreateSymlink(binaryPath, constants.GlobalPhpPath); err != nil {
+			utils.LogError(err, "shim")
+			return err
+		}
+	}
+
+	if utils.FileExists(constants.LocalComposerPath) {
+		if err := utils.CreateSymlink(constants.LocalComposerPath, constants.GlobalComposerPath); err != nil {
+			utils.LogError(err, "shim")
+			return err
+		}
+	}
+
+	return config.SetStruct("shims", &config.ShimConfig{
+		Enabled: false,
+	})
+}
+
+// WriteShim writes a small shell script to path which hands off to
+// 'yerd shim <tool>', replacing any existing symlink or shim
+// path: Location of the shim, binary: The yerd binary, tool: php or composer
+func WriteShim(path, binary, tool string) error {
+	if utils.FileExists(path) && !utils.IsSymlink(path) && !IsShim(path) {
+		return fmt.Errorf("%s exists and is not managed by yerd, refusing to replace", path)
+	}
+
+	if utils.IsSymlink(path) {
+		if err := utils.RemoveSymlink(path); err != nil {
+			utils.LogError(err, "shim")
+			return err
+		}
+	}
+
+	content := fmt.Sprintf("#!/bin/sh\n%s\nexec %s shim %s \"$@\"\n", shimMarker, shellQuote(binary), tool)
+	if err := utils.WriteStringToFile(path, content, 0755); err != nil {
+		utils.LogError(err, "shim")
+		return err
+	}
+
+	return utils.Chmod(path, 0755)
+}
+
+// shellQuote quotes value for use as a single word in a shell script
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// IsShim checks whether the file at path is a shim written by YERD
+func IsShim(path string) bool {
+	if utils.IsSymlink(path) {
+		return false
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(string(content), shimMarker)
+}
+
+// Pin writes a .php-version file into dir, owned by the real user
+// dir: Project directory, version: The PHP version to pin, eg: 8.3
+func Pin(dir, version string) (string, error) {
+	if !constants.IsValidPhpVersion(version) {
+		return "", fmt.Errorf("PHP %s is not a valid version, use one of: %s", version, strings.Join(constants.GetAvailablePhpVersions(), ", "))
+	}
+
+	path := filepath.Join(dir, PhpVersionFile)
+	if err := utils.WriteStringToFile(path, version+"\n", constants.FilePermissions); err != nil {
+		utils.LogError(err, "shim")
+		return "", err
+	}
+
+	if userCtx, err := utils.GetRealUser(); err == nil {
+		if err := utils.Chown(path, userCtx.UID, userCtx.GID); err != nil {
+			utils.LogError(err, "shim")
+			return "", err
+		}
+	}
+
+	return path, nil
+}
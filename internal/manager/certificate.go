@@ -1,43 +1,166 @@
 package manager
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/lumosolutions/yerd/internal/config"
 	"github.com/lumosolutions/yerd/internal/constants"
 	"github.com/lumosolutions/yerd/internal/utils"
 )
 
+const (
+	KeyTypeRSA   = "rsa"
+	KeyTypeECDSA = "ecdsa"
+
+	defaultValidityDays   = 3650
+	defaultCaValidityDays = 3650
+)
+
 type CertificateManager struct {
+	KeyType        string
+	ValidityDays   int
+	CaValidityDays int
+	CertsDir       string
 }
 
+// NewCertificateManager creates a certificate manager using the key type
+// and validity stored in the web configuration, falling back to defaults
 func NewCertificateManager() *CertificateManager {
-	return &CertificateManager{}
+	certConfig := config.GetWebConfig().Certs
+
+	cm := &CertificateManager{
+		KeyType:        strings.ToLower(certConfig.KeyType),
+		ValidityDays:   certConfig.ValidityDays,
+		CaValidityDays: certConfig.CaValidityDays,
+		CertsDir:       constants.CertsDir,
+	}
+
+	if cm.KeyType != KeyTypeECDSA {
+		cm.KeyType = KeyTypeRSA
+	}
+
+	if cm.ValidityDays <= 0 {
+		cm.ValidityDays = defaultValidityDays
+	}
+
+	if cm.CaValidityDays <= 0 {
+		cm.CaValidityDays = defaultCaValidityDays
+	}
+
+	return cm
 }
 
+// GenerateCaCertificate creates the named certificate authority and
+// trusts it in the system store and Chrome
 func (certManager *CertificateManager) GenerateCaCertificate(name string) error {
-	caPath := filepath.Join(constants.CertsDir, "ca")
-	keyName := name + ".key"
-	certName := name + ".crt"
+	certPath, err := certManager.createCaCertificate(name)
+	if err != nil {
+		return err
+	}
+
+	if depMan, err := NewDependencyManager(); err == nil {
+		depMan.TrustCertificate(certPath, name)
+	}
+
+	certManager.ChromeTrust(filepath.Dir(certPath), name+".crt")
+
+	return nil
+}
+
+// createCaCertificate writes the key pair of the named certificate
+// authority to the ca folder, returning the path of the certificate
+func (certManager *CertificateManager) createCaCertificate(name string) (string, error) {
+	caPath := filepath.Join(certManager.CertsDir, "ca")
+	keyPath := filepath.Join(caPath, name+".key")
+	certPath := filepath.Join(caPath, name+".crt")
 
-	utils.CreateDirectory(caPath)
+	if err := utils.CreateDirectory(caPath); err != nil {
+		return "", err
+	}
+
+	key, err := certManager.generateKey(4096)
+	if err != nil {
+		utils.LogError(err, "cacert")
+		return "", fmt.Errorf("unable to generate ca key")
+	}
 
-	if !certManager.generateKey(caPath, keyName, "4096") {
-		return fmt.Errorf("unable to generate ca key")
+	serial, err := randomSerial()
+	if err != nil {
+		utils.LogError(err, "cacert")
+		return "", fmt.Errorf("unable to generate ca serial")
 	}
 
-	params := []string{"req", "-new", "-x509", "-days", "3650", "-key", keyName, "-out", certName, "-subj", "/C=US/CN=YERD/O=YERD/OU=YERD"}
-	output, success := utils.ExecuteCommandInDir(caPath, "openssl", params...)
-	if !success {
-		utils.LogInfo("cacert", "openssl command failed")
-		utils.LogInfo("cacert", "output: %s", output)
-		return fmt.Errorf("failed to generate ca cert")
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Country:            []string{"US"},
+			CommonName:         "YERD",
+			Organization:       []string{"YERD"},
+			OrganizationalUnit: []string{"YERD"},
+		},
+		NotBefore:             now.Add(-1 * time.Hour),
+		NotAfter:              now.AddDate(0, 0, certManager.CaValidityDays),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		SubjectKeyId:          subjectKeyId(key.Public()),
 	}
 
-	depMan, _ := NewDependencyManager()
-	depMan.TrustCertificate(filepath.Join(caPath, certName), name)
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		utils.LogError(err, "cacert")
+		return "", fmt.Errorf("failed to generate ca cert")
+	}
+
+	if err := writeKeyPair(keyPath, certPath, key, der); err != nil {
+		utils.LogError(err, "cacert")
+		return "", err
+	}
+
+	return certPath, nil
+}
 
-	certManager.ChromeTrust(caPath, certName)
+// RestoreCaCertificate replaces the named CA with an existing key pair,
+// such as one exported from another machine, and trusts it
+// name: The CA name, eg: yerd, key/cert: PEM encoded key pair
+func (certManager *CertificateManager) RestoreCaCertificate(name string, key, cert []byte) error {
+	caPath := filepath.Join(certManager.CertsDir, "ca")
+	keyPath := filepath.Join(caPath, name+".key")
+	certPath := filepath.Join(caPath, name+".crt")
+
+	if err := utils.WriteToFile(keyPath, key, 0600); err != nil {
+		return err
+	}
+
+	if err := utils.WriteToFile(certPath, cert, constants.FilePermissions); err != nil {
+		return err
+	}
+
+	if _, _, err := loadKeyPair(keyPath, certPath); err != nil {
+		return fmt.Errorf("restored ca is invalid: %w", err)
+	}
+
+	if depMan, err := NewDependencyManager(); err == nil {
+		depMan.TrustCertificate(certPath, name)
+	}
+
+	certManager.ChromeTrust(caPath, name+".crt")
 
 	return nil
 }
@@ -55,83 +178,171 @@ func (certManager *CertificateManager) ChromeTrust(caPath, certName string) erro
 	return nil
 }
 
-func (certManager *CertificateManager) GenerateCert(domain, caName string) (string, string, error) {
-	certPath := filepath.Join(constants.CertsDir, "sites")
-	keyName := domain + ".key"
-	csrName := domain + ".csr"
-	certName := domain + ".crt"
-
-	caCert := filepath.Join(constants.CertsDir, "ca", caName+".crt")
-	caKey := filepath.Join(constants.CertsDir, "ca", caName+".key")
+// GenerateCert issues a certificate for domain signed by the named CA
+// domain: The primary domain, names: Every DNS name the certificate must cover
+func (certManager *CertificateManager) GenerateCert(domain string, names []string, caName string) (string, string, error) {
+	sitesPath := filepath.Join(certManager.CertsDir, "sites")
+	keyPath := filepath.Join(sitesPath, domain+".key")
+	certPath := filepath.Join(sitesPath, domain+".crt")
+
+	caCert, caKey, err := loadKeyPair(
+		filepath.Join(certManager.CertsDir, "ca", caName+".key"),
+		filepath.Join(certManager.CertsDir, "ca", caName+".crt"),
+	)
+	if err != nil {
+		utils.LogError(err, "createcerts")
+		return "", "", fmt.Errorf("unable to load the %s certificate authority", caName)
+	}
 
-	utils.CreateDirectory(certPath)
+	if err := utils.CreateDirectory(sitesPath); err != nil {
+		return "", "", err
+	}
 
-	if !certManager.generateKey(certPath, keyName, "2048") {
+	key, err := certManager.generateKey(2048)
+	if err != nil {
+		utils.LogError(err, "createcerts")
 		return "", "", fmt.Errorf("unable to generate site key")
 	}
 
-	if !certManager.generateCsr(certPath, keyName, csrName, "/C=GB/CN="+domain) {
-		return "", "", fmt.Errorf("unable to generate site csr")
+	serial, err := randomSerial()
+	if err != nil {
+		utils.LogError(err, "createcerts")
+		return "", "", fmt.Errorf("unable to generate site serial")
 	}
 
-	if !certManager.generateSiteCertificate(certPath, domain, csrName, certName, caCert, caKey) {
+	keyUsage := x509.KeyUsageDigitalSignature
+	if certManager.KeyType == KeyTypeRSA {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Country:    []string{"GB"},
+			CommonName: domain,
+		},
+		DNSNames:              names,
+		NotBefore:             now.Add(-1 * time.Hour),
+		NotAfter:              now.AddDate(0, 0, certManager.ValidityDays),
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+		SubjectKeyId:          subjectKeyId(key.Public()),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
+	if err != nil {
+		utils.LogError(err, "createcerts")
 		return "", "", fmt.Errorf("unable to generate site cert")
 	}
 
-	return filepath.Join(certPath, keyName), filepath.Join(certPath, certName), nil
+	if err := writeKeyPair(keyPath, certPath, key, der); err != nil {
+		utils.LogError(err, "createcerts")
+		return "", "", err
+	}
+
+	return keyPath, certPath, nil
 }
 
-func (certManager *CertificateManager) generateKey(folder, keyFile, keySize string) bool {
-	output, success := utils.ExecuteCommandInDir(folder, "openssl", "genrsa", "-out", keyFile, keySize)
-	if !success {
-		utils.LogInfo("generateKey", "openssl command failed")
-		utils.LogInfo("generateKey", "output: %s", output)
-		return false
+// generateKey creates a private key of the configured type, rsaBits
+// is only used when generating RSA keys
+func (certManager *CertificateManager) generateKey(rsaBits int) (crypto.Signer, error) {
+	if certManager.KeyType == KeyTypeECDSA {
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	}
 
-	return true
+	return rsa.GenerateKey(rand.Reader, rsaBits)
 }
 
-func (certManager *CertificateManager) generateCsr(folder, keyFile, csrFile, subject string) bool {
-	params := []string{"req", "-new", "-key", keyFile, "-out", csrFile, "-subj", subject}
-	output, success := utils.ExecuteCommandInDir(folder, "openssl", params...)
-	if !success {
-		utils.LogInfo("createcerts", "openssl command failed")
-		utils.LogInfo("createcerts", "output: %s", output)
-		return false
+// LoadCertificate reads and parses a PEM encoded certificate
+func LoadCertificate(certPath string) (*x509.Certificate, error) {
+	content, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
 	}
 
-	return true
+	block, _ := pem.Decode(content)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s does not contain a certificate", certPath)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
 }
 
-func (certManager *CertificateManager) generateSiteCertificate(certPath, domain, csrFileName, certFileName, caCertPath, caKeyPath string) bool {
-	content, err := utils.FetchFromGitHub("ssl", "ext.conf")
+// loadKeyPair reads a PEM encoded certificate and its private key,
+// supporting PKCS#1, PKCS#8 and EC keys generated by earlier releases
+func loadKeyPair(keyPath, certPath string) (*x509.Certificate, crypto.Signer, error) {
+	cert, err := LoadCertificate(certPath)
 	if err != nil {
-		utils.LogError(err, "createcerts")
-		utils.LogInfo("createcerts", "failed to ext.conf")
-		return false
+		return nil, nil, err
 	}
 
-	content = utils.Template(content, utils.TemplateData{
-		"domain": domain,
-	})
+	content, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, nil, fmt.Errorf("%s does not contain a private key", keyPath)
+	}
 
-	extFile := domain + ".ext"
-	extPath := filepath.Join(certPath, extFile)
-	utils.WriteStringToFile(extPath, content, constants.FilePermissions)
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
 
-	params := []string{"x509", "-req", "-in", csrFileName, "-CA", caCertPath, "-CAkey", caKeyPath, "-CAcreateserial", "-out", certFileName, "-days", "3650", "-extfile", extFile}
-	output, success := utils.ExecuteCommandInDir(certPath, "openssl", params...)
-	if !success {
-		utils.LogInfo("createcerts", "openssl command failed")
-		utils.LogInfo("createcerts", "output: %s", output)
-		return false
+	if err != nil {
+		return nil, nil, err
 	}
 
-	utils.RemoveFile(extPath)
-	utils.RemoveFile(filepath.Join(constants.CertsDir, "sites", csrFileName))
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s contains an unsupported key type", keyPath)
+	}
+
+	return cert, signer, nil
+}
+
+// writeKeyPair stores a private key (readable only by root) and its
+// certificate as PEM files
+func writeKeyPair(keyPath, certPath string, key crypto.Signer, der []byte) error {
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("unable to encode private key: %w", err)
+	}
+
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+	if err := utils.WriteToFile(keyPath, keyPem, 0600); err != nil {
+		return err
+	}
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return utils.WriteToFile(certPath, certPem, constants.FilePermissions)
+}
+
+// randomSerial generates a random 128 bit certificate serial number
+func randomSerial() (*big.Int, error) {
+	limit := new(big.Int).Lsh(big.NewInt(1), 128)
+	return rand.Int(rand.Reader, limit)
+}
+
+// subjectKeyId derives the subject key identifier from a public key
+func subjectKeyId(publicKey crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil
+	}
 
-	return true
+	sum := sha1.Sum(der)
+	return sum[:]
 }
 
 func (certManager *CertificateManager) ChromeUntrust() {
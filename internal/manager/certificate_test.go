@@ -0,0 +1,170 @@
+package manager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestCertificateManager(t *testing.T, keyType string) *CertificateManager {
+	t.Helper()
+
+	return &CertificateManager{
+		KeyType:        keyType,
+		ValidityDays:   30,
+		CaValidityDays: 365,
+		CertsDir:       t.TempDir(),
+	}
+}
+
+func assertValidFor(t *testing.T, cert *x509.Certificate, days int) {
+	t.Helper()
+
+	expected := time.Now().AddDate(0, 0, days)
+	if diff := cert.NotAfter.Sub(expected); diff < -time.Minute || diff > time.Minute {
+		t.Errorf("NotAfter = %v, want about %v", cert.NotAfter, expected)
+	}
+
+	if cert.NotBefore.After(time.Now()) {
+		t.Errorf("NotBefore = %v is in the future", cert.NotBefore)
+	}
+}
+
+func assertKeyType(t *testing.T, cert *x509.Certificate, keyType string) {
+	t.Helper()
+
+	switch keyType {
+	case KeyTypeRSA:
+		if _, ok := cert.PublicKey.(*rsa.PublicKey); !ok {
+			t.Errorf("public key is %T, want RSA", cert.PublicKey)
+		}
+	case KeyTypeECDSA:
+		if _, ok := cert.PublicKey.(*ecdsa.PublicKey); !ok {
+			t.Errorf("public key is %T, want ECDSA", cert.PublicKey)
+		}
+	}
+}
+
+func TestCreateCaCertificate(t *testing.T) {
+	for _, keyType := range []string{KeyTypeRSA, KeyTypeECDSA} {
+		t.Run(keyType, func(t *testing.T) {
+			cm := newTestCertificateManager(t, keyType)
+
+			certPath, err := cm.createCaCertificate("test")
+			if err != nil {
+				t.Fatalf("createCaCertificate: %v", err)
+			}
+
+			if certPath != filepath.Join(cm.CertsDir, "ca", "test.crt") {
+				t.Errorf("certificate written to %s", certPath)
+			}
+
+			cert, key, err := loadKeyPair(filepath.Join(cm.CertsDir, "ca", "test.key"), certPath)
+			if err != nil {
+				t.Fatalf("loadKeyPair: %v", err)
+			}
+
+			if !cert.IsCA || !cert.BasicConstraintsValid {
+				t.Error("certificate is not a CA")
+			}
+
+			if cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+				t.Error("CA cannot sign certificates")
+			}
+
+			if err := cert.CheckSignatureFrom(cert); err != nil {
+				t.Errorf("CA is not self signed: %v", err)
+			}
+
+			if public, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !public.Equal(cert.PublicKey) {
+				t.Error("private key does not match the certificate")
+			}
+
+			assertKeyType(t, cert, keyType)
+			assertValidFor(t, cert, cm.CaValidityDays)
+
+			info, err := os.Stat(filepath.Join(cm.CertsDir, "ca", "test.key"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("key permissions = %v, want 0600", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestGenerateCert(t *testing.T) {
+	site := &SiteManager{
+		Domain:   "shop.test",
+		Aliases:  []string{"api.shop.test", "admin.test"},
+		Wildcard: true,
+	}
+
+	expectedNames := []string{"shop.test", "www.shop.test", "api.shop.test", "admin.test", "*.shop.test"}
+	if names := site.certificateNames(); !slices.Equal(names, expectedNames) {
+		t.Fatalf("certificateNames = %v, want %v", names, expectedNames)
+	}
+
+	for _, keyType := range []string{KeyTypeRSA, KeyTypeECDSA} {
+		t.Run(keyType, func(t *testing.T) {
+			cm := newTestCertificateManager(t, keyType)
+
+			caPath, err := cm.createCaCertificate("test")
+			if err != nil {
+				t.Fatalf("createCaCertificate: %v", err)
+			}
+
+			keyPath, certPath, err := cm.GenerateCert(site.Domain, site.certificateNames(), "test")
+			if err != nil {
+				t.Fatalf("GenerateCert: %v", err)
+			}
+
+			cert, _, err := loadKeyPair(keyPath, certPath)
+			if err != nil {
+				t.Fatalf("loadKeyPair: %v", err)
+			}
+
+			if cert.Subject.CommonName != site.Domain {
+				t.Errorf("CommonName = %s, want %s", cert.Subject.CommonName, site.Domain)
+			}
+
+			if !slices.Equal(cert.DNSNames, expectedNames) {
+				t.Errorf("DNSNames = %v, want %v", cert.DNSNames, expectedNames)
+			}
+
+			if cert.IsCA {
+				t.Error("site certificate must not be a CA")
+			}
+
+			assertKeyType(t, cert, keyType)
+			assertValidFor(t, cert, cm.ValidityDays)
+
+			ca, err := LoadCertificate(caPath)
+			if err != nil {
+				t.Fatalf("LoadCertificate: %v", err)
+			}
+
+			roots := x509.NewCertPool()
+			roots.AddCert(ca)
+
+			for _, name := range []string{"shop.test", "www.shop.test", "api.shop.test", "admin.test", "anything.shop.test"} {
+				_, err := cert.Verify(x509.VerifyOptions{
+					DNSName:   name,
+					Roots:     roots,
+					KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+				})
+				if err != nil {
+					t.Errorf("certificate does not verify for %s: %v", name, err)
+				}
+			}
+		})
+	}
+}
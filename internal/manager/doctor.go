@@ -0,0 +1,418 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// Severity describes how serious a doctor finding is
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+
+	return "info"
+}
+
+// Finding is a single problem found by Diagnose, Fix is nil when the
+// problem cannot be repaired automatically and Hint explains what to do
+type Finding struct {
+	Area     string
+	Severity Severity
+	Message  string
+	Hint     string
+	Fix      func() error
+}
+
+type doctor struct {
+	findings   []*Finding
+	php        config.PhpConfig
+	webConfig  *config.WebConfig
+	depManager *DependencyManager
+}
+
+// Diagnose cross-checks config.json against the filesystem, systemd,
+// the hosts file and the trust store, returning every problem found
+func Diagnose() []*Finding {
+	d := &doctor{
+		php:       make(config.PhpConfig),
+		webConfig: config.GetWebConfig(),
+	}
+
+	config.GetStruct("php", &d.php)
+
+	if dm, err := NewDependencyManager(); err == nil {
+		d.depManager = dm
+	} else {
+		d.add("System", SeverityWarning, "Unable to detect the distribution: %v", err)
+	}
+
+	d.checkPhp()
+	d.checkStaleBinaries()
+	d.checkStaleServices()
+
+	if d.webConfig.Installed {
+		d.checkNginx()
+		d.checkCertificateAuthority()
+		d.checkSites()
+		d.checkOrphanedConfigs()
+		d.checkOrphanedHosts()
+	}
+
+	return d.findings
+}
+
+func (d *doctor) add(area string, severity Severity, message string, args ...any) *Finding {
+	finding := &Finding{
+		Area:     area,
+		Severity: severity,
+		Message:  fmt.Sprintf(message, args...),
+	}
+
+	d.findings = append(d.findings, finding)
+	return finding
+}
+
+// checkPhp verifies the binaries, symlinks, FPM service and system
+// dependencies of every installed PHP version
+func (d *doctor) checkPhp() {
+	shims := config.GetShimConfig().Enabled
+
+	for _, version := range d.phpVersions() {
+		info := d.php[version]
+		area := "PHP " + version
+		phpVersion := "php" + version
+
+		installPath := info.InstallPath
+		if installPath == "" {
+			installPath = filepath.Join(constants.YerdPHPDir, phpVersion)
+		}
+
+		binary := filepath.Join(installPath, "bin", "php")
+		if !utils.FileExists(binary) {
+			finding := d.add(area, SeverityError, "PHP binary %s is missing", binary)
+			finding.Hint = fmt.Sprintf("Reinstall with 'sudo yerd php %s rebuild'", version)
+			continue
+		}
+
+		localBinary := filepath.Join(constants.YerdBinDir, phpVersion)
+		if !utils.FileExists(localBinary) {
+			finding := d.add(area, SeverityError, "Symlink %s is missing or broken", localBinary)
+			finding.Fix = func() error { return utils.CreateSymlink(binary, localBinary) }
+		}
+
+		globalBinary := filepath.Join(constants.SystemBinDir, phpVersion)
+		if !utils.FileExists(globalBinary) {
+			finding := d.add(area, SeverityWarning, "Symlink %s is missing or broken", globalBinary)
+			finding.Fix = func() error { return utils.CreateSymlink(localBinary, globalBinary) }
+		} else if utils.IsConflictingCommand(phpVersion) {
+			finding := d.add(area, SeverityWarning, "'%s' resolves to an executable not managed by YERD", phpVersion)
+			finding.Hint = fmt.Sprintf("Remove the other %s from your PATH", phpVersion)
+		}
+
+		if info.IsCLI && !shims && !utils.FileExists(constants.GlobalPhpPath) {
+			finding := d.add(area, SeverityWarning, "The CLI symlink %s is missing or broken", constants.GlobalPhpPath)
+			finding.Fix = func() error { return utils.CreateSymlink(localBinary, constants.GlobalPhpPath) }
+		}
+
+		service := fmt.Sprintf("yerd-%s-fpm", phpVersion)
+		socket := filepath.Join(constants.FPMSockDir, phpVersion+"-fpm.sock")
+		if !utils.SystemdServiceActive(service) {
+			finding := d.add(area, SeverityError, "%s is not running", service)
+			finding.Fix = func() error { return utils.SystemdStartService(service) }
+		} else if !utils.FileExists(socket) {
+			finding := d.add(area, SeverityError, "FPM socket %s is missing", socket)
+			finding.Fix = func() error { return utils.SystemdRestartService(service) }
+		}
+
+		if d.depManager != nil {
+			if missing := d.depManager.CheckSystemDependencies(info.Extensions); len(missing) > 0 {
+				finding := d.add(area, SeverityWarning, "Missing system libraries for: %s", strings.Join(missing, ", "))
+				finding.Fix = func() error { return d.depManager.InstallExtensionDependencies(missing) }
+			}
+		}
+	}
+}
+
+// checkStaleBinaries finds symlinks into YERD which no longer resolve,
+// usually left behind by a removed PHP version
+func (d *doctor) checkStaleBinaries() {
+	for _, dir := range []string{constants.SystemBinDir, constants.YerdBinDir} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if !utils.IsBrokenSymlink(path) {
+				continue
+			}
+
+			target, _ := utils.ReadSymlink(path)
+			if dir == constants.SystemBinDir && !strings.HasPrefix(target, constants.YerdBaseDir) {
+				continue
+			}
+
+			finding := d.add("Binaries", SeverityWarning, "Stale symlink %s points to %s", path, target)
+			finding.Fix = func() error { return os.Remove(path) }
+		}
+	}
+}
+
+// checkStaleServices finds PHP-FPM units for versions which are no
+// longer installed
+func (d *doctor) checkStaleServices() {
+	units, _ := filepath.Glob(filepath.Join(constants.SystemdDir, "yerd-php*-fpm.service"))
+
+	for _, unit := range units {
+		service := strings.TrimSuffix(filepath.Base(unit), ".service")
+		version := strings.TrimSuffix(strings.TrimPrefix(service, "yerd-php"), "-fpm")
+		if _, installed := d.php[version]; installed {
+			continue
+		}
+
+		finding := d.add("Services", SeverityWarning, "%s exists but PHP %s is not installed", service, version)
+		finding.Fix = func() error {
+			utils.SystemdStopService(service)
+			utils.SystemdDisable(service)
+
+			if err := utils.RemoveFile(unit); err != nil {
+				return err
+			}
+
+			return utils.SystemdReload()
+		}
+	}
+}
+
+func (d *doctor) checkNginx() {
+	if !utils.SystemdServiceActive(nginxServiceName) {
+		finding := d.add("Nginx", SeverityError, "%s is not running", nginxServiceName)
+		finding.Fix = func() error { return utils.SystemdStartService(nginxServiceName) }
+	}
+
+	if output, err := TestNginxConfig(); err != nil {
+		finding := d.add("Nginx", SeverityError, "The nginx configuration is invalid")
+		finding.Hint = output
+	}
+}
+
+// checkCertificateAuthority verifies the root CA exists, has not
+// expired and is trusted by the system
+func (d *doctor) checkCertificateAuthority() {
+	caFile := filepath.Join(constants.CertsDir, "ca", "yerd.crt")
+
+	cert, err := LoadCertificate(caFile)
+	if err != nil {
+		finding := d.add("Certificates", SeverityError, "The YERD root CA is missing or unreadable")
+		finding.Fix = d.regenerateCertificateAuthority
+		return
+	}
+
+	if newCertificateInfo(caFile, cert).Expired() {
+		finding := d.add("Certificates", SeverityError, "The YERD root CA expired on %s", cert.NotAfter.Format("2006-01-02"))
+		finding.Fix = d.regenerateCertificateAuthority
+		return
+	}
+
+	if d.depManager != nil && !d.depManager.IsTrusted(caFile, "yerd") {
+		finding := d.add("Certificates", SeverityWarning, "The YERD root CA is not trusted by the system")
+		finding.Hint = "Browsers keep their own trust store, use 'yerd web trust' for those"
+		finding.Fix = func() error { return d.depManager.TrustCertificate(caFile, "yerd") }
+	}
+}
+
+// regenerateCertificateAuthority issues a new root CA, trusts it and
+// reissues every site certificate which was signed by the old one
+func (d *doctor) regenerateCertificateAuthority() error {
+	if err := NewCertificateManager().GenerateCaCertificate("yerd"); err != nil {
+		return err
+	}
+
+	sm := d.siteManager()
+	for _, domain := range d.siteDomains() {
+		if !sm.identifySite(domain) || sm.Insecure {
+			continue
+		}
+
+		if err := sm.createCertificate(); err != nil {
+			return err
+		}
+	}
+
+	return ReloadNginx()
+}
+
+// checkSites verifies the directory, nginx configuration, certificate,
+// hosts entries and pool of every site in config.json
+func (d *doctor) checkSites() {
+	hosts, _ := utils.NewHostsManager().ListYerdHosts()
+
+	for _, domain := range d.siteDomains() {
+		site := d.webConfig.Sites[domain]
+		area := "Site " + domain
+
+		sm := d.siteManager()
+		sm.identifySite(domain)
+
+		if site.Proxy == "" && !utils.IsDirectory(site.RootDirectory) {
+			finding := d.add(area, SeverityError, "Directory %s no longer exists", site.RootDirectory)
+			finding.Hint = fmt.Sprintf("Remove the site with 'sudo yerd sites remove %s'", domain)
+		}
+
+		if site.Proxy == "" && site.PhpVersion != "" {
+			if _, installed := d.php[site.PhpVersion]; !installed {
+				finding := d.add(area, SeverityError, "PHP %s is not installed", site.PhpVersion)
+				finding.Hint = fmt.Sprintf("Change it with 'sudo yerd sites set php <version> %s'", domain)
+			}
+		}
+
+		if !utils.FileExists(filepath.Join(constants.NginxSitesEnabled, domain+".conf")) {
+			finding := d.add(area, SeverityError, "The nginx configuration is missing")
+			finding.Fix = func() error {
+				return utils.RunAll(sm.createSiteConfig, sm.applyNginxConfig)
+			}
+		}
+
+		if !site.Insecure {
+			d.checkSiteCertificate(area, sm)
+		}
+
+		for _, hostname := range append([]string{domain}, site.Aliases...) {
+			if !slices.Contains(hosts, hostname) {
+				finding := d.add(area, SeverityWarning, "%s has no entry in %s", hostname, utils.HostsFilePath)
+				finding.Fix = func() error { return utils.NewHostsManager().Add(hostname) }
+			}
+		}
+
+		if site.Pool != nil && !utils.FileExists(sm.poolFile(site.PhpVersion)) {
+			finding := d.add(area, SeverityError, "The dedicated PHP-FPM pool is missing")
+			finding.Fix = sm.writePool
+		}
+	}
+}
+
+func (d *doctor) checkSiteCertificate(area string, sm *SiteManager) {
+	reissue := func() error {
+		return utils.RunAll(sm.createCertificate, ReloadNginx)
+	}
+
+	cert, err := LoadCertificate(sm.CrtFile)
+	if err != nil || !utils.FileExists(sm.KeyFile) {
+		finding := d.add(area, SeverityError, "The certificate is missing or unreadable")
+		finding.Fix = reissue
+		return
+	}
+
+	if newCertificateInfo(sm.CrtFile, cert).Expired() {
+		finding := d.add(area, SeverityWarning, "The certificate expired on %s", cert.NotAfter.Format("2006-01-02"))
+		finding.Fix = reissue
+	}
+}
+
+// checkOrphanedConfigs finds nginx configuration for sites which are
+// not in config.json
+func (d *doctor) checkOrphanedConfigs() {
+	files, _ := filepath.Glob(filepath.Join(constants.NginxSitesEnabled, "*.conf"))
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		if _, exists := d.webConfig.Sites[strings.TrimSuffix(name, ".conf")]; exists {
+			continue
+		}
+
+		finding := d.add("Nginx", SeverityWarning, "%s does not belong to any site", file)
+		finding.Fix = func() error {
+			stage, err := NewNginxStage()
+			if err != nil {
+				return err
+			}
+
+			if err := stage.Remove(name); err != nil {
+				stage.Discard()
+				return err
+			}
+
+			_, err = stage.Apply()
+			return err
+		}
+	}
+}
+
+// checkOrphanedHosts finds entries in the YERD section of the hosts
+// file which no site uses
+func (d *doctor) checkOrphanedHosts() {
+	hosts, err := utils.NewHostsManager().ListYerdHosts()
+	if err != nil {
+		d.add("Hosts", SeverityWarning, "Unable to read %s: %v", utils.HostsFilePath, err)
+		return
+	}
+
+	known := []string{}
+	for _, site := range d.webConfig.Sites {
+		known = append(known, site.Domain)
+		known = append(known, site.Aliases...)
+	}
+
+	for _, hostname := range hosts {
+		if slices.Contains(known, hostname) {
+			continue
+		}
+
+		finding := d.add("Hosts", SeverityWarning, "%s does not belong to any site", hostname)
+		finding.Fix = func() error { return utils.NewHostsManager().Remove(hostname) }
+	}
+}
+
+// siteManager returns a SiteManager whose output is discarded, so fixes
+// do not interleave spinner output with the doctor report
+func (d *doctor) siteManager() *SiteManager {
+	spinner := utils.NewSpinner("")
+	spinner.SetWriter(io.Discard)
+
+	return &SiteManager{
+		Spinner:   spinner,
+		WebConfig: d.webConfig,
+	}
+}
+
+func (d *doctor) siteDomains() []string {
+	domains := make([]string, 0, len(d.webConfig.Sites))
+	for domain := range d.webConfig.Sites {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	return domains
+}
+
+func (d *doctor) phpVersions() []string {
+	versions := make([]string, 0, len(d.php))
+	for version := range d.php {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	return versions
+}
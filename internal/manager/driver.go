@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+const DefaultDriver = "php"
+
+// Driver describes a type of project, how to recognise it and which
+// nginx location template it is served with
+type Driver struct {
+	Name   string
+	Detect func(directory string) bool
+	Public func(directory string) string
+}
+
+// drivers are checked in order, the generic php driver matches anything
+var drivers = []Driver{
+	{
+		Name:   "laravel",
+		Detect: func(directory string) bool { return utils.FileExists(filepath.Join(directory, "artisan")) },
+		Public: func(directory string) string { return "public" },
+	},
+	{
+		Name:   "symfony",
+		Detect: func(directory string) bool { return utils.FileExists(filepath.Join(directory, "bin", "console")) },
+		Public: func(directory string) string { return "public" },
+	},
+	{
+		Name:   "wordpress",
+		Detect: isWordPress,
+		Public: func(directory string) string { return "" },
+	},
+	{
+		Name:   "static",
+		Detect: isStatic,
+		Public: staticPublicFolder,
+	},
+	{
+		Name:   DefaultDriver,
+		Detect: func(directory string) bool { return true },
+		Public: func(directory string) string {
+			if utils.IsDirectory(filepath.Join(directory, "public")) {
+				return "public"
+			}
+			return ""
+		},
+	},
+}
+
+// DetectDriver returns the first driver which recognises the project
+// directory: The root directory of the project
+func DetectDriver(directory string) Driver {
+	for _, driver := range drivers {
+		if driver.Detect(directory) {
+			return driver
+		}
+	}
+
+	return drivers[len(drivers)-1]
+}
+
+// GetDriver looks up a driver by name, an empty name returns the
+// generic php driver used by sites created before drivers existed
+func GetDriver(name string) (Driver, bool) {
+	if name == "" {
+		name = DefaultDriver
+	}
+
+	for _, driver := range drivers {
+		if driver.Name == strings.ToLower(name) {
+			return driver, true
+		}
+	}
+
+	return Driver{}, false
+}
+
+// DriverNames lists the name of every available driver
+func DriverNames() []string {
+	names := []string{}
+	for _, driver := range drivers {
+		names = append(names, driver.Name)
+	}
+
+	return names
+}
+
+func isWordPress(directory string) bool {
+	for _, file := range []string{"wp-config.php", "wp-config-sample.php", "wp-load.php"} {
+		if utils.FileExists(filepath.Join(directory, file)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isStatic matches projects with an index.html and no PHP entry point
+func isStatic(directory string) bool {
+	public := filepath.Join(directory, staticPublicFolder(directory))
+	if !utils.FileExists(filepath.Join(public, "index.html")) {
+		return false
+	}
+
+	return !utils.FileExists(filepath.Join(public, "index.php"))
+}
+
+func staticPublicFolder(directory string) string {
+	for _, folder := range []string{"public", "dist", "build"} {
+		if utils.FileExists(filepath.Join(directory, folder, "index.html")) {
+			return folder
+		}
+	}
+
+	return ""
+}
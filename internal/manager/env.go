@@ -0,0 +1,188 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SetEnv stores environment variables for a site and makes them
+// available to PHP-FPM and the php shim
+// identifier: The domain or directory of the site, pairs: KEY=VALUE values
+func (sm *SiteManager) SetEnv(identifier string, pairs []string) error {
+	sm.Spinner.UpdatePhrase("Updating environment...")
+	sm.Spinner.Start()
+
+	if !sm.identifyEnvSite(identifier) {
+		return fmt.Errorf("unable to identify site")
+	}
+
+	if sm.Env == nil {
+		sm.Env = map[string]string{}
+	}
+
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			sm.Spinner.StopWithError("Expected KEY=VALUE, got '%s'", pair)
+			return fmt.Errorf("invalid environment variable")
+		}
+
+		if err := sm.validateEnv(key, value); err != nil {
+			sm.Spinner.StopWithError("%v", err)
+			return err
+		}
+
+		sm.Env[key] = value
+		sm.Spinner.AddInfoStatus("Set %s", key)
+	}
+
+	return sm.applyEnv()
+}
+
+// UnsetEnv removes environment variables from a site
+// identifier: The domain or directory of the site, keys: The variables to remove
+func (sm *SiteManager) UnsetEnv(identifier string, keys []string) error {
+	sm.Spinner.UpdatePhrase("Updating environment...")
+	sm.Spinner.Start()
+
+	if !sm.identifyEnvSite(identifier) {
+		return fmt.Errorf("unable to identify site")
+	}
+
+	for _, key := range keys {
+		if _, exists := sm.Env[key]; !exists {
+			sm.Spinner.AddWarningStatus("%s is not set", key)
+			continue
+		}
+
+		delete(sm.Env, key)
+		sm.Spinner.AddInfoStatus("Unset %s", key)
+	}
+
+	return sm.applyEnv()
+}
+
+// ListEnv prints the environment variables of a site
+// identifier: The domain or directory of the site
+func (sm *SiteManager) ListEnv(identifier string) error {
+	if !sm.identifySite(identifier) {
+		fmt.Println("Unable to identify site")
+		return fmt.Errorf("unable to identify site")
+	}
+
+	fmt.Printf("🌐 Site: %s\n", sm.Domain)
+	if len(sm.Env) == 0 {
+		fmt.Printf("└─ No environment variables set\n\n")
+		return nil
+	}
+
+	keys := sortedKeys(sm.Env)
+	for i, key := range keys {
+		prefix := "├─"
+		if i == len(keys)-1 {
+			prefix = "└─"
+		}
+		fmt.Printf("%s %s=%s\n", prefix, key, sm.Env[key])
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// identifyEnvSite identifies a site which can hold environment variables
+func (sm *SiteManager) identifyEnvSite(identifier string) bool {
+	if !sm.identifySite(identifier) {
+		sm.Spinner.StopWithError("Unable to identify site")
+		return false
+	}
+
+	if sm.Proxy != "" {
+		sm.Spinner.StopWithError("%s is a proxy site and does not use PHP", sm.Domain)
+		return false
+	}
+
+	sm.Spinner.AddSuccessStatus("Identified Site")
+	sm.Spinner.AddInfoStatus("Domain: %s", sm.Domain)
+
+	return true
+}
+
+// validateEnv checks a variable can be safely rendered into the FPM
+// pool, or nginx when the site uses the shared pool
+func (sm *SiteManager) validateEnv(key, value string) error {
+	if !envKeyPattern.MatchString(key) {
+		return fmt.Errorf("'%s' is not a valid variable name", key)
+	}
+
+	if strings.ContainsAny(value, "\"\r\n") {
+		return fmt.Errorf("the value of %s cannot contain quotes or new lines", key)
+	}
+
+	if sm.Pool == nil && strings.Contains(value, "$") {
+		return fmt.Errorf("the value of %s contains '$', use 'sudo yerd sites set pool on %s' first", key, sm.Domain)
+	}
+
+	return nil
+}
+
+// applyEnv renders the environment into the pool or nginx configuration
+// and saves it to the site's configuration
+func (sm *SiteManager) applyEnv() error {
+	steps := append([]utils.Step{sm.poolStep()}, sm.nginxSteps()...)
+	steps = append(steps, utils.Step{Name: "save configuration", Do: sm.addToConfig})
+
+	if err := utils.NewTransaction("env").Run(steps...); err != nil {
+		sm.Spinner.StopWithError("Failed to update environment, all changes have been rolled back")
+		return err
+	}
+
+	sm.Spinner.StopWithSuccess("Environment Updated")
+	return nil
+}
+
+// poolEnv renders the site's environment as PHP-FPM env[] directives
+func (sm *SiteManager) poolEnv() string {
+	lines := []string{}
+	for _, key := range sortedKeys(sm.Env) {
+		lines = append(lines, fmt.Sprintf("env[%s] = \"%s\"", key, escapeEnvValue(sm.Env[key])))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// fastcgiEnv renders the site's environment as nginx fastcgi_param
+// directives, sites with a dedicated pool receive it from the pool instead
+func (sm *SiteManager) fastcgiEnv() string {
+	if sm.Pool != nil {
+		return ""
+	}
+
+	lines := []string{}
+	for _, key := range sortedKeys(sm.Env) {
+		lines = append(lines, fmt.Sprintf("        fastcgi_param %s \"%s\";", key, escapeEnvValue(sm.Env[key])))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// escapeEnvValue escapes backslashes so a value cannot end the double
+// quoted string it is rendered into, nginx and PHP-FPM both read \\ as \
+func escapeEnvValue(value string) string {
+	return strings.ReplaceAll(value, `\`, `\\`)
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
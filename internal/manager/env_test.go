@@ -0,0 +1,67 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/lumosolutions/yerd/internal/config"
+)
+
+func TestFastcgiEnvEscapesValues(t *testing.T) {
+	sm := &SiteManager{Env: map[string]string{
+		"APP_PATH": `C:\app\`,
+		"APP_LIST": "a;b",
+	}}
+
+	expected := "        fastcgi_param APP_LIST \"a;b\";\n" +
+		"        fastcgi_param APP_PATH \"C:\\\\app\\\\\";"
+
+	if env := sm.fastcgiEnv(); env != expected {
+		t.Errorf("fastcgiEnv =\n%s\nwant\n%s", env, expected)
+	}
+}
+
+func TestFastcgiEnvWithDedicatedPool(t *testing.T) {
+	sm := &SiteManager{
+		Env:  map[string]string{"APP_ENV": "local"},
+		Pool: &config.PoolConfig{},
+	}
+
+	if env := sm.fastcgiEnv(); env != "" {
+		t.Errorf("fastcgiEnv = %q, want the environment in the pool only", env)
+	}
+}
+
+func TestPoolEnvEscapesValues(t *testing.T) {
+	sm := &SiteManager{Env: map[string]string{"APP_PATH": `C:\app\`}}
+
+	if env := sm.poolEnv(); env != `env[APP_PATH] = "C:\\app\\"` {
+		t.Errorf("poolEnv = %s", env)
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	sm := &SiteManager{Domain: "shop.test"}
+
+	valid := map[string]string{
+		"APP_ENV":  "local",
+		"APP_PATH": `C:\app\`,
+		"APP_LIST": "a;b",
+	}
+	for key, value := range valid {
+		if err := sm.validateEnv(key, value); err != nil {
+			t.Errorf("validateEnv(%s, %q): %v", key, value, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"1APP":     "value",
+		"APP_NAME": `say "hi"`,
+		"APP_LINE": "a\nb",
+		"APP_HOME": "$HOME",
+	}
+	for key, value := range invalid {
+		if err := sm.validateEnv(key, value); err == nil {
+			t.Errorf("validateEnv(%s, %q) succeeded", key, value)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package manager
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -91,6 +93,27 @@ func (dm *DependencyManager) TrustCertificate(certificate, name string) error {
 	return nil
 }
 
+// IsTrusted checks the certificate has been copied into the system trust
+// store under name, and is the same certificate which YERD holds
+func (dm *DependencyManager) IsTrusted(certificate, name string) bool {
+	certPath, err := dm.getCertPath(dm.distro)
+	if err != nil {
+		return false
+	}
+
+	trusted, err := os.ReadFile(fmt.Sprintf("%s/%s-ca.crt", certPath, name))
+	if err != nil {
+		return false
+	}
+
+	content, err := os.ReadFile(certificate)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(bytes.TrimSpace(trusted), bytes.TrimSpace(content))
+}
+
 func (dm *DependencyManager) execTrustUpdate() error {
 	switch dm.distro {
 	case "arch", "manjaro":
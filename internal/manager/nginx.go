@@ -0,0 +1,180 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+	"golang.org/x/sys/unix"
+)
+
+const nginxServiceName = "yerd-nginx"
+
+// NginxStage holds a copy of sites-enabled which site changes are
+// written to, the copy is validated with 'nginx -t' and only then
+// swapped in for the live configuration
+type NginxStage struct {
+	Dir      string
+	SitesDir string
+}
+
+// NewNginxStage creates a staging area seeded with the live site configuration
+func NewNginxStage() (*NginxStage, error) {
+	nginxConfig := constants.GetNginxConfig()
+	stage := &NginxStage{
+		Dir:      filepath.Join(nginxConfig.InstallPath, "staging"),
+		SitesDir: filepath.Join(nginxConfig.InstallPath, "staging", "sites-enabled"),
+	}
+
+	if err := utils.ReplaceDirectory(stage.Dir); err != nil {
+		utils.LogError(err, "nginx")
+		return nil, err
+	}
+
+	if err := utils.CreateDirectory(stage.SitesDir); err != nil {
+		utils.LogError(err, "nginx")
+		return nil, err
+	}
+
+	if utils.IsDirectory(constants.NginxSitesEnabled) {
+		if err := utils.CopyRecursive(constants.NginxSitesEnabled, stage.SitesDir); err != nil {
+			utils.LogError(err, "nginx")
+			return nil, err
+		}
+	}
+
+	return stage, nil
+}
+
+// Write stages the configuration file name with content
+func (stage *NginxStage) Write(name, content string) error {
+	return utils.WriteStringToFile(filepath.Join(stage.SitesDir, name), content, constants.FilePermissions)
+}
+
+// Remove stages the removal of the configuration file name
+func (stage *NginxStage) Remove(name string) error {
+	return utils.RemoveFile(filepath.Join(stage.SitesDir, name))
+}
+
+// Validate runs 'nginx -t' against the main configuration with the
+// staged sites in place of the live ones, returning the nginx output
+// when the configuration is invalid
+func (stage *NginxStage) Validate() (string, error) {
+	nginxConfig := constants.GetNginxConfig()
+
+	content, err := os.ReadFile(filepath.Join(nginxConfig.ConfigPath, "nginx.conf"))
+	if err != nil {
+		return "", err
+	}
+
+	stagedConfig := strings.ReplaceAll(string(content), constants.NginxSitesEnabled+"/", stage.SitesDir+"/")
+	stagedConfigPath := filepath.Join(stage.Dir, "nginx.conf")
+	if err := utils.WriteStringToFile(stagedConfigPath, stagedConfig, constants.FilePermissions); err != nil {
+		return "", err
+	}
+
+	return testNginxConfigFile(stagedConfigPath)
+}
+
+// Apply validates the staged configuration, swaps it with the live
+// sites-enabled directory and reloads nginx, the previous configuration
+// is restored if nginx cannot be reloaded
+func (stage *NginxStage) Apply() (string, error) {
+	defer stage.Discard()
+
+	if output, err := stage.Validate(); err != nil {
+		return output, err
+	}
+
+	if err := utils.CreateDirectory(constants.NginxSitesEnabled); err != nil {
+		return "", err
+	}
+
+	if err := swapDirectories(stage.SitesDir, constants.NginxSitesEnabled); err != nil {
+		utils.LogError(err, "nginx")
+		return "", fmt.Errorf("unable to switch to the new configuration")
+	}
+
+	if err := ReloadNginx(); err != nil {
+		utils.LogInfo("nginx", "Reload failed, restoring the previous configuration")
+		if swapErr := swapDirectories(stage.SitesDir, constants.NginxSitesEnabled); swapErr != nil {
+			utils.LogError(swapErr, "nginx")
+		}
+		ReloadNginx()
+		return "", err
+	}
+
+	return "", nil
+}
+
+// Discard removes the staging area
+func (stage *NginxStage) Discard() {
+	if err := utils.RemoveFolder(stage.Dir); err != nil {
+		utils.LogError(err, "nginx")
+	}
+}
+
+// ReloadNginx gracefully reloads nginx with 'nginx -s reload', starting
+// the service instead when it is not running
+func ReloadNginx() error {
+	if !utils.SystemdServiceActive(nginxServiceName) {
+		return utils.SystemdStartService(nginxServiceName)
+	}
+
+	nginxConfig := constants.GetNginxConfig()
+	configFile := filepath.Join(nginxConfig.ConfigPath, "nginx.conf")
+
+	if output, success := utils.ExecuteCommand(nginxConfig.BinaryPath, "-s", "reload", "-c", configFile); !success {
+		utils.LogInfo("nginx", "Failed to reload nginx")
+		utils.LogInfo("nginx", "Output: %s", output)
+		return fmt.Errorf("unable to reload nginx")
+	}
+
+	return nil
+}
+
+// TestNginxConfig runs 'nginx -t' against the YERD nginx configuration,
+// returning the nginx output when the configuration is invalid
+func TestNginxConfig() (string, error) {
+	nginxConfig := constants.GetNginxConfig()
+	return testNginxConfigFile(filepath.Join(nginxConfig.ConfigPath, "nginx.conf"))
+}
+
+func testNginxConfigFile(configFile string) (string, error) {
+	nginxConfig := constants.GetNginxConfig()
+
+	output, success := utils.ExecuteCommand(nginxConfig.BinaryPath, "-t", "-c", configFile)
+	if !success {
+		return strings.TrimSpace(output), fmt.Errorf("nginx configuration is invalid")
+	}
+
+	return "", nil
+}
+
+// swapDirectories atomically exchanges two directories, falling back
+// to a pair of renames on filesystems without RENAME_EXCHANGE support
+func swapDirectories(a, b string) error {
+	err := unix.Renameat2(unix.AT_FDCWD, a, unix.AT_FDCWD, b, unix.RENAME_EXCHANGE)
+	if err == nil {
+		return nil
+	}
+
+	utils.LogInfo("nginx", "RENAME_EXCHANGE unavailable (%v), using renames", err)
+
+	temp := b + ".swap"
+	os.RemoveAll(temp)
+
+	if err := os.Rename(b, temp); err != nil {
+		return err
+	}
+
+	if err := os.Rename(a, b); err != nil {
+		os.Rename(temp, b)
+		return err
+	}
+
+	return os.Rename(temp, a)
+}
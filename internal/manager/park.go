@@ -0,0 +1,314 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+const parkServiceName = "yerd-park"
+
+// Park registers a directory whose subdirectories are each served
+// as <subdirectory>.test, and watches it for new folders
+// directory: The folder containing the projects, eg: ~/code
+func (sm *SiteManager) Park(directory string) error {
+	sm.Spinner.UpdatePhrase("Parking directory...")
+	sm.Spinner.Start()
+
+	abs, err := filepath.Abs(directory)
+	if err != nil || !utils.IsDirectory(abs) {
+		sm.Spinner.StopWithError("Path provided is not a directory")
+		return fmt.Errorf("path not a directory")
+	}
+
+	if slices.Contains(sm.WebConfig.Parked, abs) {
+		sm.Spinner.StopWithError("%s is already parked", abs)
+		return fmt.Errorf("directory already parked")
+	}
+
+	sm.WebConfig.Parked = append(sm.WebConfig.Parked, abs)
+	if err := config.SetStruct("web.parked", sm.WebConfig.Parked); err != nil {
+		sm.Spinner.StopWithError("Unable to save configuration")
+		return err
+	}
+
+	sm.Spinner.AddSuccessStatus("Parked %s", abs)
+
+	if err := sm.writeParkWatcher(); err != nil {
+		sm.Spinner.AddWarningStatus("Unable to watch for new folders, use 'sudo yerd sites refresh'")
+	} else {
+		sm.Spinner.AddSuccessStatus("Watching for new folders")
+	}
+
+	sm.Spinner.StopWithSuccess("Directory Parked")
+
+	return RefreshParked()
+}
+
+// Unpark stops serving the subdirectories of a parked directory and
+// removes every site which was registered automatically from it
+// directory: A previously parked folder
+func (sm *SiteManager) Unpark(directory string) error {
+	sm.Spinner.UpdatePhrase("Unparking directory...")
+	sm.Spinner.Start()
+
+	abs, _ := filepath.Abs(directory)
+	if !slices.Contains(sm.WebConfig.Parked, abs) {
+		sm.Spinner.StopWithError("%s is not parked", abs)
+		return fmt.Errorf("directory not parked")
+	}
+
+	sm.WebConfig.Parked = utils.RemoveItems(sm.WebConfig.Parked, abs)
+	if err := config.SetStruct("web.parked", sm.WebConfig.Parked); err != nil {
+		sm.Spinner.StopWithError("Unable to save configuration")
+		return err
+	}
+
+	if err := sm.writeParkWatcher(); err != nil {
+		sm.Spinner.AddWarningStatus("Unable to update the directory watcher")
+	}
+
+	sm.Spinner.StopWithSuccess("Unparked %s", abs)
+
+	return RefreshParked()
+}
+
+// RefreshParked adds a site for every new folder within the parked
+// directories and removes parked sites whose folder has gone. Every
+// change is staged and nginx is reloaded once, if any site cannot be
+// added or nginx rejects the result, the whole refresh is rolled back
+func RefreshParked() error {
+	webConfig := config.GetWebConfig()
+	if webConfig.Sites == nil {
+		webConfig.Sites = map[string]config.SiteConfig{}
+	}
+
+	removed := []string{}
+	for _, site := range webConfig.Sites {
+		if site.ParkedIn == "" {
+			continue
+		}
+
+		if !slices.Contains(webConfig.Parked, site.ParkedIn) || !utils.IsDirectory(site.RootDirectory) {
+			removed = append(removed, site.Domain)
+		}
+	}
+
+	added := map[string][]string{}
+	for _, parked := range webConfig.Parked {
+		for _, directory := range findParkedProjects(parked) {
+			if !isRegisteredDirectory(webConfig, directory) {
+				added[parked] = append(added[parked], directory)
+			}
+		}
+	}
+
+	if len(removed) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	spinner := utils.NewSpinner("Refreshing Parked Directories...")
+	spinner.SetDelay(150)
+	spinner.Start()
+
+	stage, err := NewNginxStage()
+	if err != nil {
+		spinner.StopWithError("Unable to stage the nginx configuration")
+		return err
+	}
+
+	newSite := func() *SiteManager {
+		return &SiteManager{Spinner: spinner, WebConfig: webConfig, stage: stage}
+	}
+
+	removers := []*SiteManager{}
+	for _, domain := range removed {
+		remover := newSite()
+		remover.identifySite(domain)
+
+		if err := stage.Remove(domain + ".conf"); err != nil {
+			stage.Discard()
+			spinner.StopWithError("Unable to remove %s.conf", domain)
+			return err
+		}
+
+		removers = append(removers, remover)
+	}
+
+	transaction := utils.NewTransaction("refresh")
+	adders := []*SiteManager{}
+
+	for _, parked := range webConfig.Parked {
+		for _, directory := range added[parked] {
+			adder := newSite()
+			adder.Directory = directory
+			adder.ParkedIn = parked
+
+			err := utils.RunAll(adder.validateDirectory, adder.validateDomain, adder.validateAliases, adder.validatePhpVersion)
+			if err != nil {
+				spinner.AddWarningStatus("Skipped %s: %v", directory, err)
+				continue
+			}
+
+			if err := transaction.Run(adder.stageSteps()...); err != nil {
+				stage.Discard()
+				spinner.StopWithError("Failed to refresh parked directories, all changes have been rolled back")
+				return err
+			}
+
+			webConfig.Sites[adder.Domain] = config.SiteConfig{Domain: adder.Domain, RootDirectory: adder.Directory}
+			adders = append(adders, adder)
+		}
+	}
+
+	err = transaction.Run(
+		utils.Step{
+			Name: "save configuration",
+			Do: func() error {
+				for _, adder := range adders {
+					if err := adder.addToConfig(); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Undo: func() error {
+				for _, adder := range adders {
+					config.Delete(fmt.Sprintf("web.sites.[%s]", adder.Domain))
+				}
+				return nil
+			},
+		},
+		utils.Step{Name: "reload nginx", Do: newSite().applyNginxConfig},
+	)
+
+	if err != nil {
+		stage.Discard()
+		spinner.StopWithError("Failed to refresh parked directories, all changes have been rolled back")
+		return err
+	}
+
+	for _, remover := range removers {
+		remover.removeSiteFiles()
+		spinner.AddSuccessStatus("Removed %s", remover.Domain)
+	}
+
+	for _, adder := range adders {
+		spinner.AddSuccessStatus("Added %s", adder.url())
+	}
+
+	spinner.StopWithSuccess("Parked Directories Refreshed")
+	return nil
+}
+
+// findParkedProjects lists the visible subdirectories of a parked directory
+func findParkedProjects(parked string) []string {
+	entries, err := os.ReadDir(parked)
+	if err != nil {
+		utils.LogError(err, "park")
+		return []string{}
+	}
+
+	projects := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		projects = append(projects, filepath.Join(parked, entry.Name()))
+	}
+
+	return projects
+}
+
+// isRegisteredDirectory checks if a site is already served from directory
+func isRegisteredDirectory(webConfig *config.WebConfig, directory string) bool {
+	for _, site := range webConfig.Sites {
+		if site.RootDirectory == directory {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeParkWatcher renders the systemd path unit which runs
+// 'yerd sites refresh' whenever a parked directory changes
+func (sm *SiteManager) writeParkWatcher() error {
+	pathUnit := filepath.Join(constants.SystemdDir, parkServiceName+".path")
+	serviceUnit := filepath.Join(constants.SystemdDir, parkServiceName+".service")
+
+	if len(sm.WebConfig.Parked) == 0 {
+		return RemoveParkWatcher()
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		utils.LogError(err, "park")
+		return err
+	}
+
+	userCtx, err := utils.GetRealUser()
+	if err != nil {
+		utils.LogError(err, "park")
+		return err
+	}
+
+	watchPaths := []string{}
+	for _, parked := range sm.WebConfig.Parked {
+		watchPaths = append(watchPaths, "PathChanged="+parked)
+	}
+
+	units := []struct {
+		template string
+		path     string
+		data     utils.TemplateData
+	}{
+		{"park.path", pathUnit, utils.TemplateData{"watch_paths": strings.Join(watchPaths, "\n")}},
+		{"park.service", serviceUnit, utils.TemplateData{"binary": binary, "user": userCtx.Username}},
+	}
+
+	for _, unit := range units {
+		content, err := utils.LoadTemplate("web", unit.template)
+		if err != nil {
+			utils.LogError(err, "park")
+			return err
+		}
+
+		rendered, err := utils.Template(content, unit.data)
+		if err != nil {
+			utils.LogError(err, "park")
+			return err
+		}
+
+		if err := utils.WriteStringToFile(unit.path, rendered, constants.FilePermissions); err != nil {
+			utils.LogError(err, "park")
+			return err
+		}
+	}
+
+	if err := utils.SystemdReload(); err != nil {
+		return err
+	}
+
+	utils.SystemdEnable(parkServiceName + ".path")
+	utils.SystemdStopService(parkServiceName + ".path")
+	return utils.SystemdStartService(parkServiceName + ".path")
+}
+
+// RemoveParkWatcher stops and removes the systemd units which watch
+// the parked directories
+func RemoveParkWatcher() error {
+	utils.SystemdStopService(parkServiceName + ".path")
+	utils.SystemdDisable(parkServiceName + ".path")
+	utils.RemoveFile(filepath.Join(constants.SystemdDir, parkServiceName+".path"))
+	utils.RemoveFile(filepath.Join(constants.SystemdDir, parkServiceName+".service"))
+
+	return utils.SystemdReload()
+}
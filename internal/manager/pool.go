@@ -0,0 +1,273 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+var iniKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_.]+$`)
+
+// NewPoolConfig returns the default process manager limits for a site pool
+func NewPoolConfig() *config.PoolConfig {
+	return &config.PoolConfig{
+		MaxChildren:     5,
+		StartServers:    2,
+		MinSpareServers: 1,
+		MaxSpareServers: 3,
+		Ini:             map[string]string{},
+	}
+}
+
+// fpmSocket returns the socket nginx should pass PHP requests to, the
+// site's own pool if it has one, otherwise the shared pool of its version
+func (sm *SiteManager) fpmSocket() string {
+	if sm.Pool != nil {
+		return sm.poolSocket(sm.PhpVersion)
+	}
+
+	return filepath.Join(constants.FPMSockDir, fmt.Sprintf("php%s-fpm.sock", sm.PhpVersion))
+}
+
+func (sm *SiteManager) poolSocket(version string) string {
+	return filepath.Join(constants.FPMSockDir, fmt.Sprintf("php%s-%s.sock", version, sm.Domain))
+}
+
+func (sm *SiteManager) poolFile(version string) string {
+	return filepath.Join(constants.YerdEtcDir, "php"+version, constants.FPMPoolDir, "site-"+sm.Domain+".conf")
+}
+
+// writePool renders the site's FPM pool and reloads PHP-FPM, the
+// previous pool is restored if PHP-FPM rejects the configuration
+func (sm *SiteManager) writePool() error {
+	if sm.Pool == nil {
+		return nil
+	}
+
+	sm.Spinner.UpdatePhrase("Configuring PHP-FPM Pool...")
+
+	content, err := utils.LoadTemplate("php", "pool.conf")
+	if err != nil {
+		sm.Spinner.AddErrorStatus("Unable to load the pool.conf template")
+		return err
+	}
+
+	ini := []string{}
+	for _, key := range sortedKeys(sm.Pool.Ini) {
+		ini = append(ini, fmt.Sprintf("php_admin_value[%s] = %s", key, sm.Pool.Ini[key]))
+	}
+
+	content, err = utils.Template(content, utils.TemplateData{
+		"domain":            sm.Domain,
+		"version":           sm.PhpVersion,
+		"pool_name":         sm.Domain,
+		"user":              utils.GetFPMUser(),
+		"group":             utils.GetFPMGroup(),
+		"sock_path":         sm.poolSocket(sm.PhpVersion),
+		"log_path":          filepath.Join(constants.FPMLogDir, fmt.Sprintf("php%s-fpm.log", sm.PhpVersion)),
+		"max_children":      strconv.Itoa(sm.Pool.MaxChildren),
+		"start_servers":     strconv.Itoa(sm.Pool.StartServers),
+		"min_spare_servers": strconv.Itoa(sm.Pool.MinSpareServers),
+		"max_spare_servers": strconv.Itoa(sm.Pool.MaxSpareServers),
+		"ini":               strings.Join(ini, "\n"),
+		"env":               sm.poolEnv(),
+	})
+	if err != nil {
+		sm.Spinner.AddErrorStatus("Unable to render pool.conf: %v", err)
+		return err
+	}
+
+	path := sm.poolFile(sm.PhpVersion)
+	previous, readErr := os.ReadFile(path)
+
+	if err := utils.WriteStringToFile(path, content, constants.FilePermissions); err != nil {
+		sm.Spinner.AddErrorStatus("Unable to save %s", filepath.Base(path))
+		return err
+	}
+
+	if err := reloadFpm(sm.PhpVersion); err != nil {
+		if readErr == nil {
+			utils.WriteToFile(path, previous, constants.FilePermissions)
+		} else {
+			utils.RemoveFile(path)
+		}
+		reloadFpm(sm.PhpVersion)
+
+		sm.Spinner.AddErrorStatus("PHP-FPM rejected the pool configuration")
+		return err
+	}
+
+	sm.Spinner.AddSuccessStatus("Configured PHP-FPM Pool (%s)", filepath.Base(sm.poolSocket(sm.PhpVersion)))
+	return nil
+}
+
+// removePool deletes the site's pool for a PHP version and reloads PHP-FPM
+func (sm *SiteManager) removePool(version string) error {
+	path := sm.poolFile(version)
+	if !utils.FileExists(path) {
+		return nil
+	}
+
+	if err := utils.RemoveFile(path); err != nil {
+		utils.LogError(err, "pool")
+		return err
+	}
+
+	return reloadFpm(version)
+}
+
+// updatePool changes the site's pool, name is 'pool', 'pool.<setting>'
+// or 'ini.<directive>', setting any of them creates a dedicated pool
+func (sm *SiteManager) updatePool(name, value string) error {
+	if sm.Proxy != "" {
+		sm.Spinner.StopWithError("%s is a proxy site and does not use PHP", sm.Domain)
+		return fmt.Errorf("site is a proxy")
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		sm.Spinner.StopWithError("Values cannot contain new lines")
+		return fmt.Errorf("invalid value")
+	}
+
+	hadPool := sm.Pool != nil
+	if sm.Pool == nil {
+		sm.Pool = NewPoolConfig()
+	}
+
+	if sm.Pool.Ini == nil {
+		sm.Pool.Ini = map[string]string{}
+	}
+
+	setting, key, _ := strings.Cut(strings.ToLower(name), ".")
+	switch setting {
+	case "pool":
+		if key == "" {
+			return sm.togglePool(value, hadPool)
+		}
+
+		if err := setPoolLimit(sm.Pool, key, value); err != nil {
+			sm.Spinner.StopWithError("%v", err)
+			return err
+		}
+	case "ini":
+		_, directive, _ := strings.Cut(name, ".")
+		if !iniKeyPattern.MatchString(directive) {
+			sm.Spinner.StopWithError("Invalid ini directive '%s'", directive)
+			return fmt.Errorf("invalid ini directive")
+		}
+
+		if value == "" {
+			delete(sm.Pool.Ini, directive)
+		} else {
+			sm.Pool.Ini[directive] = value
+		}
+	}
+
+	if err := sm.runUpdate(append([]utils.Step{sm.poolStep()}, sm.nginxSteps()...)...); err != nil {
+		return err
+	}
+
+	sm.Spinner.AddInfoStatus("Updated %s", name)
+	sm.Spinner.StopWithSuccess("Update Successful")
+	return nil
+}
+
+// togglePool enables or disables the site's dedicated pool
+func (sm *SiteManager) togglePool(value string, hadPool bool) error {
+	enable, err := strconv.ParseBool(normaliseToggle(value))
+	if err != nil {
+		sm.Spinner.StopWithError("Expected on or off, got '%s'", value)
+		return fmt.Errorf("invalid value")
+	}
+
+	if enable {
+		err = sm.runUpdate(append([]utils.Step{sm.poolStep()}, sm.nginxSteps()...)...)
+	} else {
+		if !hadPool {
+			sm.Pool = nil
+			sm.Spinner.StopWithSuccess("%s does not have a dedicated pool", sm.Domain)
+			return nil
+		}
+
+		sm.Pool = nil
+		err = sm.runUpdate(append(sm.nginxSteps(), sm.removePoolStep(sm.PhpVersion))...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if enable {
+		sm.Spinner.StopWithSuccess("Dedicated PHP-FPM pool enabled")
+	} else {
+		sm.Spinner.StopWithSuccess("Using the shared PHP %s pool", sm.PhpVersion)
+	}
+
+	return nil
+}
+
+func setPoolLimit(pool *config.PoolConfig, key, value string) error {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		return fmt.Errorf("pool.%s must be a positive number", key)
+	}
+
+	switch key {
+	case "max_children":
+		pool.MaxChildren = number
+	case "start_servers":
+		pool.StartServers = number
+	case "min_spare_servers":
+		pool.MinSpareServers = number
+	case "max_spare_servers":
+		pool.MaxSpareServers = number
+	default:
+		return fmt.Errorf("unknown pool setting '%s', expected max_children, start_servers, min_spare_servers or max_spare_servers", key)
+	}
+
+	if pool.MinSpareServers > pool.MaxSpareServers || pool.StartServers < pool.MinSpareServers ||
+		pool.StartServers > pool.MaxSpareServers || pool.MaxSpareServers > pool.MaxChildren {
+		return fmt.Errorf("pool limits must satisfy min_spare_servers <= start_servers <= max_spare_servers <= max_children")
+	}
+
+	return nil
+}
+
+func normaliseToggle(value string) string {
+	switch strings.ToLower(value) {
+	case "on", "yes", "enable", "enabled":
+		return "true"
+	case "off", "no", "disable", "disabled":
+		return "false"
+	}
+
+	return value
+}
+
+// reloadFpm validates the PHP-FPM configuration of a version and
+// gracefully reloads it
+func reloadFpm(version string) error {
+	phpVersion := "php" + version
+	fpmBinary := filepath.Join(constants.YerdPHPDir, phpVersion, "sbin", "php-fpm")
+	fpmConfig := filepath.Join(constants.YerdEtcDir, phpVersion, "php-fpm.conf")
+
+	if output, success := utils.ExecuteCommand(fpmBinary, "--fpm-config", fpmConfig, "-t"); !success {
+		utils.LogInfo("pool", "PHP-FPM %s configuration test failed", version)
+		utils.LogInfo("pool", "Output: %s", output)
+		return fmt.Errorf("php-fpm %s configuration is invalid", version)
+	}
+
+	service := fmt.Sprintf("yerd-%s-fpm", phpVersion)
+	if !utils.SystemdServiceActive(service) {
+		return utils.SystemdStartService(service)
+	}
+
+	return utils.SystemdReloadService(service)
+}
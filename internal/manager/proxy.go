@@ -0,0 +1,89 @@
+package manager
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// AddProxy creates a site which forwards every request, including
+// websocket upgrades, to an upstream server such as a Vite or Node dev server
+// domain: The domain to serve, eg: app.test, upstream: eg: http://127.0.0.1:5173
+func (sm *SiteManager) AddProxy(domain, upstream string) error {
+	sm.Domain = domain
+	sm.Proxy = upstream
+
+	sm.Spinner.Start()
+
+	steps := []utils.Step{
+		{Name: "validate proxy domain", Do: sm.validateProxyDomain},
+		{Name: "validate domain", Do: sm.validateDomain},
+		{Name: "validate aliases", Do: sm.validateAliases},
+		{Name: "validate upstream", Do: sm.validateUpstream},
+	}
+
+	err := utils.NewTransaction("addproxy").Run(append(steps, sm.provisionSteps()...)...)
+	if err != nil {
+		sm.Spinner.StopWithError("Failed to add proxy, all changes have been rolled back")
+		return err
+	}
+
+	sm.Spinner.StopWithSuccess("Proxy Created!  %s -> %s", sm.url(), sm.Proxy)
+
+	return nil
+}
+
+// updateProxy points an existing proxy site at a new upstream
+func (sm *SiteManager) updateProxy(upstream string) error {
+	if sm.Proxy == "" {
+		sm.Spinner.StopWithError("%s is not a proxy site", sm.Domain)
+		return fmt.Errorf("site is not a proxy")
+	}
+
+	sm.Proxy = upstream
+
+	steps := append([]utils.Step{{Name: "validate upstream", Do: sm.validateUpstream}}, sm.nginxSteps()...)
+
+	if err := sm.runUpdate(steps...); err != nil {
+		return err
+	}
+
+	sm.Spinner.AddInfoStatus("Proxying to %s", sm.Proxy)
+	sm.Spinner.StopWithSuccess("Update Successful")
+
+	return nil
+}
+
+// validateProxyDomain ensures a domain was given, as proxy sites have
+// no directory to derive one from
+func (sm *SiteManager) validateProxyDomain() error {
+	if strings.TrimSpace(sm.Domain) == "" {
+		sm.Spinner.AddErrorStatus("A domain is required for proxy sites")
+		return fmt.Errorf("domain required")
+	}
+
+	return nil
+}
+
+// validateUpstream checks the upstream is an absolute http(s) URL
+func (sm *SiteManager) validateUpstream() error {
+	sm.Spinner.UpdatePhrase("Validating Upstream...")
+
+	upstream := strings.TrimSpace(sm.Proxy)
+	if !strings.Contains(upstream, "://") {
+		upstream = "http://" + upstream
+	}
+
+	parsed, err := url.Parse(upstream)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		sm.Spinner.AddErrorStatus("Upstream %s is invalid, eg: http://127.0.0.1:5173", sm.Proxy)
+		return fmt.Errorf("invalid upstream")
+	}
+
+	sm.Proxy = strings.TrimSuffix(parsed.String(), "/")
+	sm.Spinner.AddInfoStatus("Upstream: %s", sm.Proxy)
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package manager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// runUpdate applies a change to an existing site and saves it, every
+// step is rolled back if any of them fail
+func (sm *SiteManager) runUpdate(steps ...utils.Step) error {
+	steps = append(steps, utils.Step{Name: "save configuration", Do: sm.addToConfig})
+
+	if err := utils.NewTransaction("updatesite").Run(steps...); err != nil {
+		sm.Spinner.StopWithError("Failed to update site, all changes have been rolled back")
+		return err
+	}
+
+	return nil
+}
+
+// fileSnapshot holds the content of files before a step changes them, a
+// nil entry records a file which did not exist
+type fileSnapshot map[string][]byte
+
+// snapshotFiles records the current content of paths
+func snapshotFiles(paths ...string) fileSnapshot {
+	snapshot := fileSnapshot{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			content = nil
+		}
+		snapshot[path] = content
+	}
+
+	return snapshot
+}
+
+// restore puts every file back as it was when the snapshot was taken
+func (snapshot fileSnapshot) restore(perm os.FileMode) error {
+	errs := []error{}
+	for path, content := range snapshot {
+		if content == nil {
+			errs = append(errs, utils.RemoveFile(path))
+			continue
+		}
+
+		errs = append(errs, utils.WriteToFile(path, content, perm))
+	}
+
+	return errors.Join(errs...)
+}
+
+// poolStep writes the site's pool, the undo puts back the pool it
+// replaced, or removes it if there was none
+func (sm *SiteManager) poolStep() utils.Step {
+	var snapshot fileSnapshot
+	var version string
+
+	return utils.Step{
+		Name: "php-fpm pool",
+		Do: func() error {
+			version = sm.PhpVersion
+			snapshot = snapshotFiles(sm.poolFile(version))
+			return sm.writePool()
+		},
+		Undo: func() error {
+			if err := snapshot.restore(constants.FilePermissions); err != nil {
+				return err
+			}
+			return reloadFpm(version)
+		},
+	}
+}
+
+// removePoolStep removes the site's pool for version, the undo puts it back
+func (sm *SiteManager) removePoolStep(version string) utils.Step {
+	var snapshot fileSnapshot
+
+	return utils.Step{
+		Name: "remove php-fpm pool",
+		Do: func() error {
+			snapshot = snapshotFiles(sm.poolFile(version))
+			return sm.removePool(version)
+		},
+		Undo: func() error {
+			if err := snapshot.restore(constants.FilePermissions); err != nil {
+				return err
+			}
+			return reloadFpm(version)
+		},
+	}
+}
+
+// certificateStep issues the site's certificate, the undo puts back the
+// certificate and key it replaced, as does a failure part way through
+func (sm *SiteManager) certificateStep() utils.Step {
+	var snapshot fileSnapshot
+
+	return utils.Step{
+		Name: "certificate",
+		Do: func() error {
+			sitesPath := filepath.Join(constants.CertsDir, "sites")
+			snapshot = snapshotFiles(
+				filepath.Join(sitesPath, sm.Domain+".crt"),
+				filepath.Join(sitesPath, sm.Domain+".key"),
+			)
+
+			if err := sm.createCertificate(); err != nil {
+				if restoreErr := snapshot.restore(0600); restoreErr != nil {
+					utils.LogError(restoreErr, "certificate")
+				}
+				return err
+			}
+
+			return nil
+		},
+		Undo: func() error {
+			return snapshot.restore(0600)
+		},
+	}
+}
+
+// hostsStep adds the hosts entries for the site and its aliases, the
+// undo removes only the entries which were not already present
+func (sm *SiteManager) hostsStep() utils.Step {
+	var added []string
+
+	return utils.Step{
+		Name: "hosts entries",
+		Do: func() error {
+			existing, _ := utils.NewHostsManager().ListYerdHosts()
+			added = []string{}
+			for _, hostname := range append([]string{sm.Domain}, sm.Aliases...) {
+				if !slices.Contains(existing, hostname) {
+					added = append(added, hostname)
+				}
+			}
+			return sm.createHostsEntry()
+		},
+		Undo: func() error {
+			hm := utils.NewHostsManager()
+			errs := []error{}
+			for _, hostname := range added {
+				errs = append(errs, hm.Remove(hostname))
+			}
+			return errors.Join(errs...)
+		},
+	}
+}
+
+// nginxSteps renders the site's nginx configuration and applies it, the
+// undo puts back the configuration which was live beforehand
+func (sm *SiteManager) nginxSteps() []utils.Step {
+	var previous fileSnapshot
+	name := ""
+
+	return []utils.Step{
+		{Name: "nginx configuration", Do: sm.createSiteConfig, Undo: sm.discardNginxStage},
+		{
+			Name: "reload nginx",
+			Do: func() error {
+				name = sm.Domain + ".conf"
+				previous = snapshotFiles(filepath.Join(constants.NginxSitesEnabled, name))
+				return sm.applyNginxConfig()
+			},
+			Undo: func() error {
+				stage, err := sm.nginxStage()
+				if err != nil {
+					return err
+				}
+
+				content := previous[filepath.Join(constants.NginxSitesEnabled, name)]
+				if content == nil {
+					err = stage.Remove(name)
+				} else {
+					err = stage.Write(name, string(content))
+				}
+
+				if err != nil {
+					sm.discardNginxStage()
+					return err
+				}
+
+				return sm.applyNginxConfig()
+			},
+		},
+	}
+}
@@ -0,0 +1,233 @@
+package manager
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// CertificateInfo describes a certificate stored within constants.CertsDir
+type CertificateInfo struct {
+	Path      string
+	Subject   string
+	Names     []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	IsCA      bool
+}
+
+// Expired checks if the certificate is no longer valid
+func (info *CertificateInfo) Expired() bool {
+	return time.Now().After(info.NotAfter)
+}
+
+// DaysRemaining returns the number of whole days until the certificate expires
+func (info *CertificateInfo) DaysRemaining() int {
+	return int(time.Until(info.NotAfter).Hours() / 24)
+}
+
+// Secure issues a certificate for a site and switches its nginx
+// configuration to HTTPS
+// identifier: The domain or directory of the site
+func (sm *SiteManager) Secure(identifier string) error {
+	sm.Spinner.UpdatePhrase("Securing site...")
+	sm.Spinner.Start()
+
+	if !sm.identifySite(identifier) {
+		sm.Spinner.StopWithError("Unable to identify site")
+		return fmt.Errorf("unable to identify site")
+	}
+
+	sm.Spinner.AddSuccessStatus("Identified Site")
+	sm.Spinner.AddInfoStatus("Domain: %s", sm.Domain)
+
+	return sm.secure()
+}
+
+// secure issues a certificate for the identified site and serves it over HTTPS
+func (sm *SiteManager) secure() error {
+	if !sm.Insecure && utils.FileExists(sm.CrtFile) {
+		sm.Spinner.StopWithSuccess("%s is already secure", sm.Domain)
+		return nil
+	}
+
+	sm.Insecure = false
+
+	steps := append([]utils.Step{sm.certificateStep()}, sm.nginxSteps()...)
+	steps = append(steps, utils.Step{Name: "save configuration", Do: sm.addToConfig})
+
+	if err := utils.NewTransaction("secure").Run(steps...); err != nil {
+		sm.Spinner.StopWithError("Failed to secure site, all changes have been rolled back")
+		return err
+	}
+
+	sm.Spinner.StopWithSuccess("Site Secured!  %s", sm.url())
+	return nil
+}
+
+// Unsecure serves a site over plain HTTP and removes its certificate
+// identifier: The domain or directory of the site
+func (sm *SiteManager) Unsecure(identifier string) error {
+	sm.Spinner.UpdatePhrase("Unsecuring site...")
+	sm.Spinner.Start()
+
+	if !sm.identifySite(identifier) {
+		sm.Spinner.StopWithError("Unable to identify site")
+		return fmt.Errorf("unable to identify site")
+	}
+
+	sm.Spinner.AddSuccessStatus("Identified Site")
+	sm.Spinner.AddInfoStatus("Domain: %s", sm.Domain)
+
+	return sm.unsecure()
+}
+
+// unsecure serves the identified site over HTTP and removes its certificate
+func (sm *SiteManager) unsecure() error {
+	if sm.Insecure {
+		sm.Spinner.StopWithSuccess("%s is already served over HTTP", sm.Domain)
+		return nil
+	}
+
+	sm.Insecure = true
+
+	steps := append(sm.nginxSteps(), utils.Step{Name: "save configuration", Do: sm.addToConfig})
+
+	if err := utils.NewTransaction("unsecure").Run(steps...); err != nil {
+		sm.Spinner.StopWithError("Failed to unsecure site, all changes have been rolled back")
+		return err
+	}
+
+	for _, file := range []string{sm.CrtFile, sm.KeyFile} {
+		if err := utils.RemoveFile(file); err != nil {
+			utils.LogError(err, "unsecure")
+		}
+	}
+
+	sm.Spinner.AddSuccessStatus("Removed certificate")
+	sm.Spinner.StopWithSuccess("Site Unsecured!  %s", sm.url())
+	return nil
+}
+
+// RenewCertificates reissues the certificates for the given sites, or
+// every secure site when none are given, then reloads nginx
+// identifiers: The domains or directories of the sites to renew
+func (sm *SiteManager) RenewCertificates(identifiers []string) error {
+	sm.Spinner.UpdatePhrase("Renewing certificates...")
+	sm.Spinner.Start()
+
+	if len(identifiers) == 0 {
+		for _, site := range sm.WebConfig.Sites {
+			if !site.Insecure {
+				identifiers = append(identifiers, site.Domain)
+			}
+		}
+		sort.Strings(identifiers)
+	}
+
+	if len(identifiers) == 0 {
+		sm.Spinner.StopWithSuccess("No secure sites to renew")
+		return nil
+	}
+
+	// Each site is renewed in its own transaction, a site which fails
+	// keeps its previous certificate without affecting the others
+	renewed := []*utils.Transaction{}
+	failed := 0
+	for _, identifier := range identifiers {
+		if !sm.identifySite(identifier) {
+			sm.Spinner.AddErrorStatus("Unable to identify site %s", identifier)
+			failed++
+			continue
+		}
+
+		if sm.Insecure {
+			sm.Spinner.AddWarningStatus("%s is not secure, use 'sudo yerd sites secure %s'", sm.Domain, sm.Domain)
+			continue
+		}
+
+		transaction := utils.NewTransaction("renew")
+		if err := transaction.Run(sm.certificateStep()); err != nil {
+			sm.Spinner.AddInfoStatus("%s keeps its previous certificate", sm.Domain)
+			failed++
+			continue
+		}
+
+		renewed = append(renewed, transaction)
+		sm.Spinner.AddInfoStatus("Renewed %s", sm.Domain)
+	}
+
+	sm.Spinner.UpdatePhrase("Reloading Nginx...")
+	if err := ReloadNginx(); err != nil {
+		for _, transaction := range renewed {
+			transaction.Rollback()
+		}
+		sm.Spinner.StopWithError("Failed to reload nginx, the previous certificates have been restored")
+		return err
+	}
+
+	sm.Spinner.AddSuccessStatus("Reloaded Nginx")
+
+	if failed > 0 {
+		sm.Spinner.StopWithError("Unable to renew %d certificate(s)", failed)
+		return fmt.Errorf("unable to renew %d certificates", failed)
+	}
+
+	sm.Spinner.StopWithSuccess("Certificates Renewed")
+	return nil
+}
+
+// ListCertificates reads every certificate stored under constants.CertsDir
+func ListCertificates() ([]*CertificateInfo, error) {
+	certificates := []*CertificateInfo{}
+
+	err := filepath.WalkDir(constants.CertsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || filepath.Ext(path) != ".crt" {
+			return nil
+		}
+
+		cert, err := LoadCertificate(path)
+		if err != nil {
+			utils.LogError(err, "certs")
+			return nil
+		}
+
+		certificates = append(certificates, newCertificateInfo(path, cert))
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(certificates, func(i, j int) bool {
+		if certificates[i].IsCA != certificates[j].IsCA {
+			return certificates[i].IsCA
+		}
+
+		return certificates[i].Path < certificates[j].Path
+	})
+
+	return certificates, nil
+}
+
+func newCertificateInfo(path string, cert *x509.Certificate) *CertificateInfo {
+	return &CertificateInfo{
+		Path:      path,
+		Subject:   cert.Subject.String(),
+		Names:     cert.DNSNames,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		IsCA:      cert.IsCA,
+	}
+}
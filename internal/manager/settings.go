@@ -0,0 +1,275 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// updateSecure switches the site between HTTPS and plain HTTP
+func (sm *SiteManager) updateSecure(value string) error {
+	secure, err := strconv.ParseBool(normaliseToggle(value))
+	if err != nil {
+		sm.Spinner.StopWithError("Expected on or off, got '%s'", value)
+		return fmt.Errorf("invalid value")
+	}
+
+	if secure {
+		return sm.secure()
+	}
+
+	return sm.unsecure()
+}
+
+// updatePublic changes the folder, relative to the site directory,
+// which nginx serves
+func (sm *SiteManager) updatePublic(folder string) error {
+	if sm.Proxy != "" {
+		sm.Spinner.StopWithError("%s is a proxy site and has no public directory", sm.Domain)
+		return fmt.Errorf("site is a proxy")
+	}
+
+	folder = strings.Trim(filepath.Clean("/"+folder), "/")
+	if !utils.IsDirectory(filepath.Join(sm.Directory, folder)) {
+		sm.Spinner.StopWithError("%s is not a directory", filepath.Join(sm.Directory, folder))
+		return fmt.Errorf("public folder does not exist")
+	}
+
+	sm.PublicFolder = folder
+
+	if err := sm.runUpdate(sm.nginxSteps()...); err != nil {
+		return err
+	}
+
+	sm.Spinner.AddInfoStatus("Serving from /%s", sm.PublicFolder)
+	sm.Spinner.StopWithSuccess("Update Successful")
+	return nil
+}
+
+// updateDirectory points the site at a different project directory,
+// keeping its domain, certificate and settings
+func (sm *SiteManager) updateDirectory(directory string) error {
+	if sm.Proxy != "" {
+		sm.Spinner.StopWithError("%s is a proxy site and has no directory", sm.Domain)
+		return fmt.Errorf("site is a proxy")
+	}
+
+	abs, err := filepath.Abs(directory)
+	if err != nil || !utils.IsDirectory(abs) {
+		sm.Spinner.StopWithError("Path provided is not a directory")
+		return fmt.Errorf("path not a directory")
+	}
+
+	for _, site := range sm.WebConfig.Sites {
+		if site.Domain != sm.Domain && site.RootDirectory == abs {
+			sm.Spinner.StopWithError("Directory is already registered to %s", site.Domain)
+			return fmt.Errorf("directory in use")
+		}
+	}
+
+	if !utils.IsDirectory(filepath.Join(abs, sm.PublicFolder)) {
+		sm.Spinner.StopWithError("%s does not contain the public folder /%s", abs, sm.PublicFolder)
+		sm.Spinner.AddInfoStatus("Change it first with 'sudo yerd sites set public <folder> %s'", sm.Domain)
+		return fmt.Errorf("public folder does not exist")
+	}
+
+	sm.Directory = abs
+	if sm.ParkedIn != "" && filepath.Dir(abs) != sm.ParkedIn {
+		sm.ParkedIn = ""
+		sm.Spinner.AddInfoStatus("Site is no longer managed by its parked directory")
+	}
+
+	if err := sm.runUpdate(sm.nginxSteps()...); err != nil {
+		return err
+	}
+
+	sm.Spinner.AddInfoStatus("Directory: %s", sm.Directory)
+	sm.Spinner.StopWithSuccess("Update Successful")
+	return nil
+}
+
+// updateDomain renames the site, issuing a certificate and hosts entry
+// for the new domain and moving its nginx configuration, snippets and
+// pool, the old files are only removed once nginx accepts the change
+func (sm *SiteManager) updateDomain(domain string) error {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	oldDomain := sm.Domain
+
+	if domain == "" || domain == oldDomain {
+		sm.Spinner.StopWithError("A new domain is required")
+		return fmt.Errorf("domain unchanged")
+	}
+
+	if sm.isDomainInUse(domain) || sm.WebConfig.Sites[domain].Domain != "" {
+		sm.Spinner.StopWithError("Domain %s is already in use", domain)
+		return fmt.Errorf("domain in use")
+	}
+
+	if strings.Contains(domain, "*") || strings.Contains(domain, "/") {
+		sm.Spinner.StopWithError("Domain %s is invalid", domain)
+		return fmt.Errorf("invalid domain")
+	}
+
+	old := *sm
+	oldConfig := sm.WebConfig.Sites[oldDomain]
+	oldSnippets := sm.snippetsDir()
+	var oldNginx fileSnapshot
+
+	sm.Domain = domain
+	sm.Aliases = utils.RemoveItems(sm.Aliases, domain)
+	sm.CrtFile = filepath.Join(constants.CertsDir, "sites", domain+".crt")
+	sm.KeyFile = filepath.Join(constants.CertsDir, "sites", domain+".key")
+
+	steps := []utils.Step{
+		{
+			Name: "move snippets",
+			Do: func() error {
+				if !utils.IsDirectory(oldSnippets) {
+					return nil
+				}
+				return os.Rename(oldSnippets, sm.snippetsDir())
+			},
+			Undo: func() error {
+				if !utils.IsDirectory(sm.snippetsDir()) {
+					return nil
+				}
+				return os.Rename(sm.snippetsDir(), oldSnippets)
+			},
+		},
+		{Name: "php-fpm pool", Do: sm.writePool, Undo: func() error { return sm.removePool(sm.PhpVersion) }},
+		{
+			Name: "certificate",
+			Do: func() error {
+				if sm.Insecure {
+					return nil
+				}
+				return sm.createCertificate()
+			},
+			Undo: sm.removeCertificate,
+		},
+		{
+			Name: "nginx configuration",
+			Do: func() error {
+				stage, err := sm.nginxStage()
+				if err != nil {
+					return err
+				}
+				if err := stage.Remove(oldDomain + ".conf"); err != nil {
+					return err
+				}
+				return sm.createSiteConfig()
+			},
+			Undo: sm.discardNginxStage,
+		},
+		sm.hostsStep(),
+		{
+			Name: "reload nginx",
+			Do: func() error {
+				oldNginx = snapshotFiles(filepath.Join(constants.NginxSitesEnabled, oldDomain+".conf"))
+				return sm.applyNginxConfig()
+			},
+			Undo: func() error {
+				stage, err := sm.nginxStage()
+				if err != nil {
+					return err
+				}
+
+				err = stage.Remove(sm.Domain + ".conf")
+				if content := oldNginx[filepath.Join(constants.NginxSitesEnabled, oldDomain+".conf")]; err == nil && content != nil {
+					err = stage.Write(oldDomain+".conf", string(content))
+				}
+
+				if err != nil {
+					sm.discardNginxStage()
+					return err
+				}
+
+				return sm.applyNginxConfig()
+			},
+		},
+		{
+			Name: "save configuration",
+			Do: func() error {
+				if err := sm.addToConfig(); err != nil {
+					sm.Spinner.AddErrorStatus("Unable to save the site configuration")
+					return err
+				}
+
+				if err := config.Delete(fmt.Sprintf("web.sites.[%s]", oldDomain)); err != nil {
+					config.Delete(fmt.Sprintf("web.sites.[%s]", sm.Domain))
+					sm.Spinner.AddErrorStatus("Unable to save the site configuration")
+					return err
+				}
+
+				return nil
+			},
+			Undo: func() error {
+				config.Delete(fmt.Sprintf("web.sites.[%s]", sm.Domain))
+				return config.SetStruct(fmt.Sprintf("web.sites.[%s]", oldDomain), oldConfig)
+			},
+		},
+	}
+
+	if err := utils.NewTransaction("rename").Run(steps...); err != nil {
+		sm.Spinner.StopWithError("Failed to rename site, all changes have been rolled back")
+		return err
+	}
+
+	hm := utils.NewHostsManager()
+	if !slices.Contains(sm.Aliases, oldDomain) {
+		hm.Remove(oldDomain)
+	}
+
+	utils.RemoveFile(old.CrtFile)
+	utils.RemoveFile(old.KeyFile)
+
+	if old.Pool != nil {
+		if err := old.removePool(old.PhpVersion); err != nil {
+			sm.Spinner.AddWarningStatus("Unable to remove the old PHP-FPM pool")
+		}
+	}
+
+	sm.Spinner.AddInfoStatus("Renamed %s to %s", oldDomain, sm.Domain)
+	sm.Spinner.StopWithSuccess("Update Successful  %s", sm.url())
+	return nil
+}
+
+// updateAliases replaces the additional domains served by the site with
+// a comma separated list, an empty value removes every alias
+func (sm *SiteManager) updateAliases(value string) error {
+	previous := sm.Aliases
+	sm.Aliases = strings.Split(value, ",")
+
+	nginx := sm.nginxSteps()
+	steps := []utils.Step{
+		{Name: "validate aliases", Do: sm.validateAliases},
+		sm.certificateStep(),
+		nginx[0],
+		sm.hostsStep(),
+		nginx[1],
+	}
+
+	if err := sm.runUpdate(steps...); err != nil {
+		return err
+	}
+
+	hm := utils.NewHostsManager()
+	for _, alias := range utils.RemoveItems(previous, sm.Aliases...) {
+		if alias != sm.Domain {
+			hm.Remove(alias)
+		}
+	}
+
+	if len(sm.Aliases) == 0 {
+		sm.Spinner.AddInfoStatus("Removed every alias")
+	}
+	sm.Spinner.StopWithSuccess("Update Successful")
+	return nil
+}
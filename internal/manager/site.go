@@ -1,9 +1,12 @@
 package manager
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"slices"
+	"sort"
 	"strings"
 
 	"github.com/lumosolutions/yerd/internal/config"
@@ -20,6 +23,15 @@ type SiteManager struct {
 	PublicFolder string
 	CrtFile      string
 	KeyFile      string
+	ParkedIn     string
+	Aliases      []string
+	Wildcard     bool
+	Insecure     bool
+	Proxy        string
+	Type         string
+	Pool         *config.PoolConfig
+	Env          map[string]string
+	stage        *NginxStage
 }
 
 func NewSiteManager() (*SiteManager, error) {
@@ -38,7 +50,33 @@ func NewSiteManager() (*SiteManager, error) {
 	}, nil
 }
 
+// siteOutput is the machine readable form of a site
+type siteOutput struct {
+	Domain     string   `json:"domain" yaml:"domain"`
+	URL        string   `json:"url" yaml:"url"`
+	Directory  string   `json:"directory,omitempty" yaml:"directory,omitempty"`
+	Public     string   `json:"public,omitempty" yaml:"public,omitempty"`
+	PhpVersion string   `json:"php_version,omitempty" yaml:"php_version,omitempty"`
+	Type       string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Secure     bool     `json:"secure" yaml:"secure"`
+	Aliases    []string `json:"aliases" yaml:"aliases"`
+	Wildcard   bool     `json:"wildcard" yaml:"wildcard"`
+	Proxy      string   `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	ParkedIn   string   `json:"parked_in,omitempty" yaml:"parked_in,omitempty"`
+	Pool       bool     `json:"dedicated_pool" yaml:"dedicated_pool"`
+}
+
+type sitesOutput struct {
+	Sites  []siteOutput `json:"sites" yaml:"sites"`
+	Parked []string     `json:"parked" yaml:"parked"`
+}
+
 func (sm *SiteManager) ListSites() {
+	if utils.IsStructuredOutput() {
+		sm.outputStructuredSites()
+		return
+	}
+
 	if len(sm.WebConfig.Sites) == 0 {
 		sm.Spinner.AddWarningStatus("No Sites Created")
 		sm.Spinner.AddInfoStatus("Create a site with one of the following commands:")
@@ -49,12 +87,99 @@ func (sm *SiteManager) ListSites() {
 	}
 
 	for _, site := range sm.WebConfig.Sites {
-		fmt.Printf("🌐 Site: %s  (PHP %s)\n", site.Domain, site.PhpVersion)
-		fmt.Printf("├─ Secure Link: https://%s/\n", site.Domain)
-		fmt.Printf("└─ Directory: %s\n\n", site.RootDirectory)
+		if site.Proxy != "" {
+			fmt.Printf("🌐 Site: %s  (Proxy)\n", site.Domain)
+		} else {
+			fmt.Printf("🌐 Site: %s  (PHP %s)\n", site.Domain, site.PhpVersion)
+			if driver, found := GetDriver(site.Type); found {
+				fmt.Printf("├─ Type: %s\n", driver.Name)
+			}
+		}
+		if site.Insecure {
+			fmt.Printf("├─ Link: http://%s/\n", site.Domain)
+		} else {
+			fmt.Printf("├─ Secure Link: https://%s/\n", site.Domain)
+		}
+		if len(site.Aliases) > 0 {
+			fmt.Printf("├─ Aliases: %s\n", strings.Join(site.Aliases, ", "))
+		}
+		if site.Wildcard {
+			fmt.Printf("├─ Wildcard: *.%s\n", site.Domain)
+		}
+		if site.Pool != nil {
+			fmt.Printf("├─ FPM Pool: dedicated (max children %d)\n", site.Pool.MaxChildren)
+		}
+		if site.ParkedIn != "" {
+			fmt.Printf("├─ Parked In: %s\n", site.ParkedIn)
+		}
+		if site.Proxy != "" {
+			fmt.Printf("└─ Upstream: %s\n\n", site.Proxy)
+		} else {
+			fmt.Printf("└─ Directory: %s\n\n", site.RootDirectory)
+		}
+	}
+
+	if len(sm.WebConfig.Parked) > 0 {
+		fmt.Printf("📁 Parked Directories\n")
+		for i, parked := range sm.WebConfig.Parked {
+			prefix := "├─"
+			if i == len(sm.WebConfig.Parked)-1 {
+				prefix = "└─"
+			}
+			fmt.Printf("%s %s\n", prefix, parked)
+		}
+		fmt.Println()
 	}
 }
 
+func (sm *SiteManager) outputStructuredSites() {
+	output := sitesOutput{
+		Sites:  []siteOutput{},
+		Parked: []string{},
+	}
+
+	output.Parked = append(output.Parked, sm.WebConfig.Parked...)
+
+	domains := make([]string, 0, len(sm.WebConfig.Sites))
+	for domain := range sm.WebConfig.Sites {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	for _, domain := range domains {
+		site := sm.WebConfig.Sites[domain]
+		entry := siteOutput{
+			Domain:   site.Domain,
+			URL:      "https://" + site.Domain,
+			Secure:   !site.Insecure,
+			Aliases:  []string{},
+			Wildcard: site.Wildcard,
+			Proxy:    site.Proxy,
+			ParkedIn: site.ParkedIn,
+			Pool:     site.Pool != nil,
+		}
+
+		if site.Insecure {
+			entry.URL = "http://" + site.Domain
+		}
+
+		entry.Aliases = append(entry.Aliases, site.Aliases...)
+
+		if site.Proxy == "" {
+			entry.Directory = site.RootDirectory
+			entry.Public = site.PublicDirectory
+			entry.PhpVersion = site.PhpVersion
+			if driver, found := GetDriver(site.Type); found {
+				entry.Type = driver.Name
+			}
+		}
+
+		output.Sites = append(output.Sites, entry)
+	}
+
+	utils.PrintStructured(output)
+}
+
 func (sm *SiteManager) SetValue(name, value, site string) error {
 	sm.Spinner.UpdatePhrase("Updating Site...")
 	sm.Spinner.Start()
@@ -66,11 +191,34 @@ func (sm *SiteManager) SetValue(name, value, site string) error {
 
 	sm.Spinner.AddSuccessStatus("Identified Site")
 	sm.Spinner.AddInfoStatus("Domain: %s", sm.Domain)
-	sm.Spinner.AddInfoStatus("Directory: %s", sm.Directory)
+	if sm.Proxy != "" {
+		sm.Spinner.AddInfoStatus("Upstream: %s", sm.Proxy)
+	} else {
+		sm.Spinner.AddInfoStatus("Directory: %s", sm.Directory)
+	}
+
+	lowerName := strings.ToLower(name)
+	if lowerName == "pool" || strings.HasPrefix(lowerName, "pool.") || strings.HasPrefix(lowerName, "ini.") {
+		return sm.updatePool(name, value)
+	}
 
-	switch strings.ToLower(name) {
+	switch lowerName {
 	case "php":
 		return sm.updatePhp(value)
+	case "proxy":
+		return sm.updateProxy(value)
+	case "type":
+		return sm.updateType(value)
+	case "domain":
+		return sm.updateDomain(value)
+	case "public":
+		return sm.updatePublic(value)
+	case "directory":
+		return sm.updateDirectory(value)
+	case "secure":
+		return sm.updateSecure(value)
+	case "aliases":
+		return sm.updateAliases(value)
 	default:
 		sm.Spinner.StopWithError("Unknown setting name %s", name)
 		return fmt.Errorf("unknown setting name")
@@ -78,25 +226,57 @@ func (sm *SiteManager) SetValue(name, value, site string) error {
 }
 
 func (sm *SiteManager) updatePhp(version string) error {
+	if sm.Proxy != "" {
+		sm.Spinner.StopWithError("%s is a proxy site and does not use PHP", sm.Domain)
+		return fmt.Errorf("site is a proxy")
+	}
+
+	previousVersion := sm.PhpVersion
 	sm.PhpVersion = version
-	if err := sm.validatePhpVersion(); err != nil {
-		sm.Spinner.StopWithError("Failed to update site")
+
+	steps := append([]utils.Step{
+		{Name: "validate php version", Do: sm.validatePhpVersion},
+		sm.poolStep(),
+	}, sm.nginxSteps()...)
+
+	if err := sm.runUpdate(steps...); err != nil {
 		return err
 	}
 
-	if err := sm.createSiteConfig(); err != nil {
-		sm.Spinner.StopWithError("Failed to update site")
-		return err
+	if sm.Pool != nil && previousVersion != sm.PhpVersion {
+		if err := sm.removePool(previousVersion); err != nil {
+			sm.Spinner.AddWarningStatus("Unable to remove the PHP %s pool", previousVersion)
+		}
 	}
 
-	if err := sm.restartNginx(); err != nil {
-		sm.Spinner.StopWithError("Failed to restart nginx")
-		return err
+	sm.Spinner.AddInfoStatus("Updated to PHP %s", sm.PhpVersion)
+	sm.Spinner.StopWithSuccess("Update Successful")
+
+	return nil
+}
+
+func (sm *SiteManager) updateType(siteType string) error {
+	if sm.Proxy != "" {
+		sm.Spinner.StopWithError("%s is a proxy site and has no type", sm.Domain)
+		return fmt.Errorf("site is a proxy")
 	}
 
-	config.SetStringData(fmt.Sprintf("web.sites.[%s].php_version", sm.Domain), sm.PhpVersion)
+	driver, found := GetDriver(siteType)
+	if !found {
+		sm.Spinner.AddInfoStatus("Available types: %s", strings.Join(DriverNames(), ", "))
+		sm.Spinner.StopWithError("Unknown site type %s", siteType)
+		return fmt.Errorf("unknown site type")
+	}
 
-	sm.Spinner.AddInfoStatus("Updated to PHP %s", sm.PhpVersion)
+	sm.Type = driver.Name
+
+	steps := append([]utils.Step{{Name: "validate php version", Do: sm.validatePhpVersion}}, sm.nginxSteps()...)
+
+	if err := sm.runUpdate(steps...); err != nil {
+		return err
+	}
+
+	sm.Spinner.AddInfoStatus("Updated to %s", sm.Type)
 	sm.Spinner.StopWithSuccess("Update Successful")
 
 	return nil
@@ -116,14 +296,44 @@ func (sm *SiteManager) RemoveSite(identifier string) error {
 	sm.Spinner.AddInfoStatus("Domain: %s", sm.Domain)
 	sm.Spinner.AddInfoStatus("Directory: %s", sm.Directory)
 
-	nginxPath := filepath.Join(constants.YerdWebDir, "nginx")
-	files := []string{
-		filepath.Join(constants.YerdWebDir, "certs", "sites", sm.Domain+".key"),
-		filepath.Join(constants.YerdWebDir, "certs", "sites", sm.Domain+".crt"),
-		filepath.Join(nginxPath, "sites-enabled", sm.Domain+".conf"),
+	stage, err := sm.nginxStage()
+	if err != nil {
+		sm.Spinner.StopWithError("Unable to stage the nginx configuration")
+		return err
 	}
 
-	utils.SystemdStopService("yerd-nginx")
+	if err := stage.Remove(sm.Domain + ".conf"); err != nil {
+		sm.Spinner.StopWithError("Unable to remove %s.conf", sm.Domain)
+		return err
+	}
+
+	if err := sm.applyNginxConfig(); err != nil {
+		sm.Spinner.StopWithError("Failed to remove site")
+		return err
+	}
+
+	sm.Spinner.AddSuccessStatus("Removed %s.conf", sm.Domain)
+	sm.removeSiteFiles()
+
+	sm.Spinner.StopWithSuccess("Site Removed")
+	return nil
+}
+
+// removeSiteFiles removes the pool, certificate, snippets, hosts entries
+// and configuration of a site whose nginx configuration has been removed
+func (sm *SiteManager) removeSiteFiles() {
+	if sm.Pool != nil {
+		if err := sm.removePool(sm.PhpVersion); err != nil {
+			sm.Spinner.AddInfoStatus("Unable to remove the PHP-FPM pool")
+		} else {
+			sm.Spinner.AddSuccessStatus("Removed PHP-FPM pool")
+		}
+	}
+
+	files := []string{
+		filepath.Join(constants.CertsDir, "sites", sm.Domain+".key"),
+		filepath.Join(constants.CertsDir, "sites", sm.Domain+".crt"),
+	}
 
 	for _, file := range files {
 		if err := utils.RemoveFile(file); err != nil {
@@ -133,19 +343,17 @@ func (sm *SiteManager) RemoveSite(identifier string) error {
 		}
 	}
 
-	if err := utils.SystemdStartService("yerd-nginx"); err != nil {
-		sm.Spinner.AddInfoStatus("Unable to restart nginx")
-	} else {
-		sm.Spinner.AddSuccessStatus("Restarted Nginx")
+	if err := utils.RemoveFolder(sm.snippetsDir()); err != nil {
+		sm.Spinner.AddInfoStatus("Unable to remove custom snippets")
 	}
 
 	hm := utils.NewHostsManager()
 	hm.Remove(sm.Domain)
+	for _, alias := range sm.Aliases {
+		hm.Remove(alias)
+	}
 
 	config.Delete(fmt.Sprintf("web.sites.[%s]", sm.Domain))
-
-	sm.Spinner.StopWithSuccess("Site Removed")
-	return nil
 }
 
 func (sm *SiteManager) identifySite(identifier string) bool {
@@ -156,6 +364,13 @@ func (sm *SiteManager) identifySite(identifier string) bool {
 			sm.Directory = site.RootDirectory
 			sm.PublicFolder = site.PublicDirectory
 			sm.PhpVersion = site.PhpVersion
+			sm.Aliases = site.Aliases
+			sm.Wildcard = site.Wildcard
+			sm.Insecure = site.Insecure
+			sm.Proxy = site.Proxy
+			sm.Type = site.Type
+			sm.Pool = site.Pool
+			sm.Env = site.Env
 			sm.CrtFile = filepath.Join(constants.CertsDir, "sites", site.Domain+".crt")
 			sm.KeyFile = filepath.Join(constants.CertsDir, "sites", site.Domain+".key")
 
@@ -174,38 +389,61 @@ func (siteManager *SiteManager) AddSite(directory, domain, publicFolder, phpVers
 
 	siteManager.Spinner.Start()
 
-	err := utils.RunAll(
-		func() error { return siteManager.validateDirectory() },
-		func() error { return siteManager.validateDomain() },
-		func() error { return siteManager.validatePhpVersion() },
-		func() error { return siteManager.createCertificate() },
-		func() error { return siteManager.createSiteConfig() },
-		func() error { return siteManager.createHostsEntry() },
-		func() error { return siteManager.restartNginx() },
-		func() error { return siteManager.addToConfig() },
-	)
+	steps := []utils.Step{
+		{Name: "validate directory", Do: siteManager.validateDirectory},
+		{Name: "validate domain", Do: siteManager.validateDomain},
+		{Name: "validate aliases", Do: siteManager.validateAliases},
+		{Name: "validate php version", Do: siteManager.validatePhpVersion},
+	}
 
+	err := utils.NewTransaction("addsite").Run(append(steps, siteManager.provisionSteps()...)...)
 	if err != nil {
-		siteManager.Spinner.StopWithError("Failed to add site")
+		siteManager.Spinner.StopWithError("Failed to add site, all changes have been rolled back")
 		return err
 	}
 
-	siteManager.Spinner.StopWithSuccess("Site Created!  https://%s", siteManager.Domain)
+	siteManager.Spinner.StopWithSuccess("Site Created!  %s", siteManager.url())
 
 	return nil
 }
 
+// provisionSteps returns the steps which create every artifact of a new
+// site, each paired with the action which removes it again
+func (sm *SiteManager) provisionSteps() []utils.Step {
+	return append(sm.stageSteps(),
+		utils.Step{Name: "reload nginx", Do: sm.applyNginxConfig, Undo: sm.removeNginxConfig},
+		utils.Step{Name: "save configuration", Do: sm.addToConfig},
+	)
+}
+
+// stageSteps returns the steps which create the files of a new site and
+// stage its nginx configuration, without making it live
+func (sm *SiteManager) stageSteps() []utils.Step {
+	return []utils.Step{
+		{Name: "php-fpm pool", Do: sm.writePool, Undo: func() error { return sm.removePool(sm.PhpVersion) }},
+		{Name: "certificate", Do: sm.createCertificate, Undo: sm.removeCertificate},
+		{Name: "nginx configuration", Do: sm.createSiteConfig, Undo: sm.discardNginxStage},
+		{Name: "hosts entries", Do: sm.createHostsEntry, Undo: sm.removeHostsEntries},
+	}
+}
+
 func (sm *SiteManager) addToConfig() error {
 	siteConfig := &config.SiteConfig{
 		RootDirectory:   sm.Directory,
 		PublicDirectory: sm.PublicFolder,
 		PhpVersion:      sm.PhpVersion,
 		Domain:          sm.Domain,
+		ParkedIn:        sm.ParkedIn,
+		Aliases:         sm.Aliases,
+		Wildcard:        sm.Wildcard,
+		Insecure:        sm.Insecure,
+		Proxy:           sm.Proxy,
+		Type:            sm.Type,
+		Pool:            sm.Pool,
+		Env:             sm.Env,
 	}
 
-	config.SetStruct(fmt.Sprintf("web.sites.[%s]", sm.Domain), siteConfig)
-
-	return nil
+	return config.SetStruct(fmt.Sprintf("web.sites.[%s]", sm.Domain), siteConfig)
 }
 
 func (siteManager *SiteManager) validateDirectory() error {
@@ -224,9 +462,23 @@ func (siteManager *SiteManager) validateDirectory() error {
 	siteManager.Directory = abs
 	siteManager.Spinner.AddInfoStatus("Directory: %s", abs)
 
+	driver := DetectDriver(abs)
+	if siteManager.Type != "" {
+		selected, found := GetDriver(siteManager.Type)
+		if !found {
+			siteManager.Spinner.AddErrorStatus("Unknown site type %s", siteManager.Type)
+			siteManager.Spinner.AddInfoStatus("Available types: %s", strings.Join(DriverNames(), ", "))
+			return fmt.Errorf("unknown site type")
+		}
+		driver = selected
+	}
+
+	siteManager.Type = driver.Name
+	siteManager.Spinner.AddInfoStatus("Site Type: %s", driver.Name)
+
 	if siteManager.PublicFolder == "" {
-		if utils.IsDirectory(filepath.Join(abs, "public")) {
-			siteManager.PublicFolder = "public"
+		siteManager.PublicFolder = driver.Public(abs)
+		if siteManager.PublicFolder != "" {
 			siteManager.Spinner.AddInfoStatus("Public directory discovered")
 		}
 	}
@@ -251,8 +503,13 @@ func (siteManager *SiteManager) validateDirectory() error {
 }
 
 func (sm *SiteManager) createCertificate() error {
+	if sm.Insecure {
+		sm.Spinner.AddInfoStatus("Serving over plain HTTP")
+		return nil
+	}
+
 	cm := NewCertificateManager()
-	keyFile, certFile, err := cm.GenerateCert(sm.Domain, "yerd")
+	keyFile, certFile, err := cm.GenerateCert(sm.Domain, sm.certificateNames(), "yerd")
 	if err != nil {
 		sm.Spinner.AddErrorStatus("Unable to secure site")
 		return err
@@ -262,7 +519,7 @@ func (sm *SiteManager) createCertificate() error {
 	sm.KeyFile = keyFile
 
 	sm.Spinner.AddSuccessStatus("Site Secured Successfully")
-	sm.Spinner.AddInfoStatus("https://%s/", sm.Domain)
+	sm.Spinner.AddInfoStatus("%s/", sm.url())
 
 	return nil
 }
@@ -278,7 +535,7 @@ func (siteManager *SiteManager) validateDomain() error {
 
 	if siteManager.WebConfig.Sites != nil {
 		for _, site := range siteManager.WebConfig.Sites {
-			if site.Domain == siteManager.Domain {
+			if site.Domain == siteManager.Domain || slices.Contains(site.Aliases, siteManager.Domain) {
 				siteManager.Spinner.AddErrorStatus("Domain is already in use")
 				siteManager.Spinner.AddInfoStatus("Use the -d flag to specify a custom domain")
 				return fmt.Errorf("domain in use")
@@ -291,9 +548,95 @@ func (siteManager *SiteManager) validateDomain() error {
 	return nil
 }
 
+func (siteManager *SiteManager) validateAliases() error {
+	aliases := []string{}
+	for _, alias := range siteManager.Aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if alias == "" || alias == siteManager.Domain || slices.Contains(aliases, alias) {
+			continue
+		}
+
+		if strings.Contains(alias, "*") {
+			siteManager.Spinner.AddErrorStatus("Alias %s is invalid, use --wildcard for subdomains", alias)
+			return fmt.Errorf("invalid alias")
+		}
+
+		if siteManager.isDomainInUse(alias) {
+			siteManager.Spinner.AddErrorStatus("Alias %s is already in use", alias)
+			return fmt.Errorf("alias in use")
+		}
+
+		aliases = append(aliases, alias)
+	}
+
+	siteManager.Aliases = aliases
+
+	if len(aliases) > 0 {
+		siteManager.Spinner.AddInfoStatus("Aliases: %s", strings.Join(aliases, ", "))
+	}
+
+	if siteManager.Wildcard {
+		siteManager.Spinner.AddInfoStatus("Wildcard: *.%s", siteManager.Domain)
+	}
+
+	return nil
+}
+
+// isDomainInUse checks if a hostname is used as a domain or alias by
+// any site other than the one being managed
+func (siteManager *SiteManager) isDomainInUse(hostname string) bool {
+	for _, site := range siteManager.WebConfig.Sites {
+		if site.Domain == siteManager.Domain {
+			continue
+		}
+
+		if site.Domain == hostname || slices.Contains(site.Aliases, hostname) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// url returns the base URL the site is served from
+func (siteManager *SiteManager) url() string {
+	if siteManager.Insecure {
+		return "http://" + siteManager.Domain
+	}
+
+	return "https://" + siteManager.Domain
+}
+
+// serverNames returns the nginx server_name values for the site
+func (siteManager *SiteManager) serverNames() []string {
+	names := append([]string{siteManager.Domain}, siteManager.Aliases...)
+	if siteManager.Wildcard {
+		names = append(names, "*."+siteManager.Domain)
+	}
+
+	return names
+}
+
+// certificateNames returns every DNS name the site certificate covers
+func (siteManager *SiteManager) certificateNames() []string {
+	names := []string{siteManager.Domain, "www." + siteManager.Domain}
+	names = append(names, siteManager.Aliases...)
+	if siteManager.Wildcard {
+		names = append(names, "*."+siteManager.Domain)
+	}
+
+	return names
+}
+
 func (siteManager *SiteManager) validatePhpVersion() error {
 	siteManager.Spinner.UpdatePhrase("Validating PHP Version...")
 
+	if siteManager.Type == "static" && siteManager.PhpVersion == "" {
+		siteManager.Spinner.AddInfoStatus("Static site, PHP is not required")
+		siteManager.Pool = nil
+		return nil
+	}
+
 	if siteManager.PhpVersion == "" {
 		versions := constants.GetAvailablePhpVersions()
 		slices.Reverse(versions)
@@ -318,57 +661,198 @@ func (siteManager *SiteManager) validatePhpVersion() error {
 }
 
 func (siteManager *SiteManager) createSiteConfig() error {
-	siteManager.Spinner.UpdatePhrase("Downloading site.conf...")
-	content, err := utils.FetchFromGitHub("nginx", "site.conf")
+	templateName := "site.conf"
+	if siteManager.Proxy != "" {
+		templateName = "proxy.conf"
+	}
+
+	siteManager.Spinner.UpdatePhrase(fmt.Sprintf("Rendering %s...", templateName))
+	content, err := utils.LoadTemplate("nginx", templateName)
 	if err != nil {
-		siteManager.Spinner.AddErrorStatus("Unable to download site.conf")
+		siteManager.Spinner.AddErrorStatus("Unable to load the %s template", templateName)
+		return err
 	}
 
 	projectPath := filepath.Join(siteManager.Directory, siteManager.PublicFolder)
-	content = utils.Template(content, utils.TemplateData{
-		"domain":      siteManager.Domain,
-		"path":        projectPath,
-		"php_version": siteManager.PhpVersion,
-		"cert":        siteManager.CrtFile,
-		"key":         siteManager.KeyFile,
-	})
-
-	path := filepath.Join(constants.YerdWebDir, "nginx", "sites-enabled", siteManager.Domain+".conf")
-	err = utils.WriteStringToFile(
-		path,
-		content,
-		constants.FilePermissions,
-	)
+	data := utils.TemplateData{
+		"domain":       siteManager.Domain,
+		"aliases":      siteManager.Aliases,
+		"secure":       !siteManager.Insecure,
+		"pool":         siteManager.Pool != nil,
+		"server_names": strings.Join(siteManager.serverNames(), " "),
+		"path":         projectPath,
+		"php_version":  siteManager.PhpVersion,
+		"cert":         siteManager.CrtFile,
+		"key":          siteManager.KeyFile,
+		"upstream":     siteManager.Proxy,
+		"snippets":     siteManager.snippetsDir(),
+		"fpm_socket":   siteManager.fpmSocket(),
+		"fastcgi_env":  siteManager.fastcgiEnv(),
+	}
 
+	if err := utils.CreateDirectory(siteManager.snippetsDir()); err != nil {
+		siteManager.Spinner.AddErrorStatus("Unable to create the snippets directory")
+		return err
+	}
+
+	if siteManager.Proxy == "" {
+		locations, err := siteManager.driverLocations(data)
+		if err != nil {
+			return err
+		}
+		data["locations"] = locations
+	}
+
+	content, err = utils.Template(content, data)
 	if err != nil {
-		siteManager.Spinner.AddErrorStatus("Unable to save %s", filepath.Base(path))
+		siteManager.Spinner.AddErrorStatus("Unable to render %s: %v", templateName, err)
 		return err
 	}
 
-	siteManager.Spinner.AddSuccessStatus("Created Nginx Configration (%s)", filepath.Base(path))
+	stage, err := siteManager.nginxStage()
+	if err != nil {
+		siteManager.Spinner.AddErrorStatus("Unable to stage the nginx configuration")
+		return err
+	}
 
+	name := siteManager.Domain + ".conf"
+	if err := stage.Write(name, content); err != nil {
+		siteManager.Spinner.AddErrorStatus("Unable to save %s", name)
+		return err
+	}
+
+	siteManager.Spinner.AddSuccessStatus("Created Nginx Configration (%s)", name)
+
+	return nil
+}
+
+// driverLocations renders the nginx root and location blocks for the
+// site's driver, these are inserted into the server block of site.conf
+func (siteManager *SiteManager) driverLocations(data utils.TemplateData) (string, error) {
+	driver, found := GetDriver(siteManager.Type)
+	if !found {
+		siteManager.Spinner.AddErrorStatus("Unknown site type %s", siteManager.Type)
+		return "", fmt.Errorf("unknown site type")
+	}
+
+	content, err := utils.LoadTemplate("nginx/drivers", driver.Name+".conf")
+	if err != nil {
+		siteManager.Spinner.AddErrorStatus("Unable to load the %s template", driver.Name)
+		return "", err
+	}
+
+	locations, err := utils.Template(content, data)
+	if err != nil {
+		siteManager.Spinner.AddErrorStatus("Unable to render the %s template: %v", driver.Name, err)
+		return "", err
+	}
+
+	return strings.TrimRight(locations, "\n"), nil
+}
+
+// removeCertificate deletes the site's certificate and key
+func (sm *SiteManager) removeCertificate() error {
+	return errors.Join(utils.RemoveFile(sm.CrtFile), utils.RemoveFile(sm.KeyFile))
+}
+
+// removeHostsEntries deletes the hosts entries for the site and its aliases
+func (sm *SiteManager) removeHostsEntries() error {
+	hm := utils.NewHostsManager()
+	errs := []error{}
+	for _, hostname := range append([]string{sm.Domain}, sm.Aliases...) {
+		errs = append(errs, hm.Remove(hostname))
+	}
+
+	return errors.Join(errs...)
+}
+
+// discardNginxStage throws away staged nginx changes which were never
+// applied, and the snippets directory if nothing was added to it
+func (sm *SiteManager) discardNginxStage() error {
+	if sm.stage != nil {
+		sm.stage.Discard()
+		sm.stage = nil
+	}
+
+	os.Remove(sm.snippetsDir())
 	return nil
 }
 
+// removeNginxConfig removes the site's live nginx configuration
+func (sm *SiteManager) removeNginxConfig() error {
+	stage, err := sm.nginxStage()
+	if err != nil {
+		return err
+	}
+
+	if err := stage.Remove(sm.Domain + ".conf"); err != nil {
+		stage.Discard()
+		sm.stage = nil
+		return err
+	}
+
+	return sm.applyNginxConfig()
+}
+
 func (siteManager *SiteManager) createHostsEntry() error {
 	hostManager := utils.NewHostsManager()
-	if err := hostManager.Add(siteManager.Domain); err != nil {
-		siteManager.Spinner.AddErrorStatus("Unable to add hosts entry")
-		utils.LogError(err, "hosts")
-		return err
+	for _, hostname := range append([]string{siteManager.Domain}, siteManager.Aliases...) {
+		if err := hostManager.Add(hostname); err != nil {
+			siteManager.Spinner.AddErrorStatus("Unable to add hosts entry for %s", hostname)
+			utils.LogError(err, "hosts")
+			return err
+		}
+	}
+
+	if siteManager.Wildcard && !siteManager.WebConfig.DNS {
+		siteManager.Spinner.AddWarningStatus("Subdomains of %s cannot be added to /etc/hosts", siteManager.Domain)
+		siteManager.Spinner.AddWarningStatus("Use 'sudo yerd web dns install' to resolve them")
 	}
 
 	return nil
 }
 
-func (siteManager *SiteManager) restartNginx() error {
-	siteManager.Spinner.UpdatePhrase("Restarting Nginx...")
-	utils.SystemdStopService("yerd-nginx")
-	if err := utils.SystemdStartService("yerd-nginx"); err != nil {
+// nginxStage returns the staging area site configuration is written to,
+// creating it from the live configuration on first use
+func (siteManager *SiteManager) nginxStage() (*NginxStage, error) {
+	if siteManager.stage != nil {
+		return siteManager.stage, nil
+	}
+
+	stage, err := NewNginxStage()
+	if err != nil {
+		return nil, err
+	}
+
+	siteManager.stage = stage
+	return stage, nil
+}
+
+// applyNginxConfig validates the staged configuration and, if nginx
+// accepts it, makes it live and reloads nginx without downtime
+func (siteManager *SiteManager) applyNginxConfig() error {
+	siteManager.Spinner.UpdatePhrase("Validating Nginx Configuration...")
+
+	stage, err := siteManager.nginxStage()
+	if err != nil {
+		siteManager.Spinner.AddErrorStatus("Unable to stage the nginx configuration")
+		return err
+	}
+	siteManager.stage = nil
+
+	output, err := stage.Apply()
+	if err != nil {
 		utils.LogError(err, "nginx")
-		siteManager.Spinner.AddErrorStatus("Failed to restart nginx")
+		siteManager.Spinner.AddErrorStatus("Nginx configuration was not applied: %v", err)
+		for _, line := range strings.Split(output, "\n") {
+			if strings.TrimSpace(line) != "" {
+				siteManager.Spinner.AddInfoStatus("%s", line)
+			}
+		}
+		siteManager.Spinner.AddInfoStatus("The previous configuration is still active")
 		return err
 	}
 
+	siteManager.Spinner.AddSuccessStatus("Reloaded Nginx")
 	return nil
 }
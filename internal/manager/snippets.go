@@ -0,0 +1,163 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+const (
+	DefaultSnippetFile = "custom.conf"
+
+	snippetHeader = `# Custom nginx directives for %s
+#
+# Every *.conf file in this directory is included inside the site's
+# server block and is kept when YERD regenerates the site configuration.
+#
+# Examples:
+#   client_max_body_size 512M;
+#   add_header X-Frame-Options SAMEORIGIN;
+#   location /storage/ { expires 7d; }
+`
+)
+
+// snippetsDir returns the directory of custom nginx snippets for the site
+func (sm *SiteManager) snippetsDir() string {
+	return filepath.Join(constants.NginxSnippetsDir, sm.Domain)
+}
+
+// EditSnippets opens a site's custom nginx snippet in the user's editor,
+// keeping the change only if nginx accepts the resulting configuration
+// identifier: The domain or directory of the site, file: The snippet file name
+func (sm *SiteManager) EditSnippets(identifier, file string) error {
+	red := color.New(color.FgRed)
+	blue := color.New(color.FgBlue)
+	green := color.New(color.FgGreen)
+
+	if !sm.identifySite(identifier) {
+		red.Println("Unable to identify site")
+		return fmt.Errorf("unable to identify site")
+	}
+
+	if utils.IsNonInteractive() {
+		red.Println("Editing snippets requires an interactive terminal")
+		blue.Printf("- Write the snippet to %s instead\n", sm.snippetsDir())
+		return utils.ErrInteractionRequired
+	}
+
+	if file == "" {
+		file = DefaultSnippetFile
+	}
+
+	if !strings.HasSuffix(file, ".conf") {
+		file += ".conf"
+	}
+
+	if filepath.Base(file) != file {
+		red.Println("Snippet name must be a file name, not a path")
+		return fmt.Errorf("invalid snippet name")
+	}
+
+	if err := utils.CreateDirectory(sm.snippetsDir()); err != nil {
+		red.Println("Unable to create the snippets directory")
+		return err
+	}
+
+	if err := sm.includeSnippets(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(sm.snippetsDir(), file)
+	previous, readErr := os.ReadFile(path)
+	existed := readErr == nil
+
+	if !existed {
+		if err := utils.WriteStringToFile(path, fmt.Sprintf(snippetHeader, sm.Domain), constants.FilePermissions); err != nil {
+			red.Println("Unable to create the snippet file")
+			return err
+		}
+	}
+
+	if err := openEditor(path); err != nil {
+		red.Println("Unable to open an editor, set $EDITOR and try again")
+		blue.Printf("- %v\n", err)
+		return err
+	}
+
+	if output, err := TestNginxConfig(); err != nil {
+		red.Println("❌ nginx rejected the configuration:")
+		fmt.Println(output)
+
+		rejected := path + ".rejected"
+		if err := utils.Copy(path, rejected); err == nil {
+			blue.Printf("- Your changes were saved to %s\n", rejected)
+		}
+
+		if existed {
+			utils.WriteToFile(path, previous, constants.FilePermissions)
+		} else {
+			utils.RemoveFile(path)
+		}
+
+		blue.Println("- The previous snippet has been restored")
+		return err
+	}
+
+	if err := ReloadNginx(); err != nil {
+		red.Println("Unable to reload nginx")
+		return err
+	}
+
+	green.Printf("✓ Snippet saved and nginx reloaded (%s)\n", path)
+	return nil
+}
+
+// includeSnippets regenerates the site configuration if it was created
+// before snippets were supported and does not include the directory
+func (sm *SiteManager) includeSnippets() error {
+	content, err := os.ReadFile(filepath.Join(constants.NginxSitesEnabled, sm.Domain+".conf"))
+	if err == nil && strings.Contains(string(content), sm.snippetsDir()) {
+		return nil
+	}
+
+	sm.Spinner.UpdatePhrase("Updating site configuration...")
+	sm.Spinner.Start()
+
+	err = utils.RunAll(
+		func() error { return sm.createSiteConfig() },
+		func() error { return sm.applyNginxConfig() },
+	)
+
+	if err != nil {
+		sm.Spinner.StopWithError("Unable to update the site configuration")
+		return err
+	}
+
+	sm.Spinner.StopWithSuccess("Site configuration now includes custom snippets")
+	return nil
+}
+
+// openEditor runs $VISUAL, $EDITOR or vi against path, attached to the terminal
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
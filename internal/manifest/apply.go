@@ -0,0 +1,306 @@
+package manifest
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/config"
+	"github.com/lumosolutions/yerd/internal/constants"
+	"github.com/lumosolutions/yerd/internal/installers/composer"
+	"github.com/lumosolutions/yerd/internal/installers/nginx"
+	phpinstaller "github.com/lumosolutions/yerd/internal/installers/php"
+	"github.com/lumosolutions/yerd/internal/manager"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+// Change is a single difference between the manifest and the current
+// environment, Apply converges that part of the environment
+type Change struct {
+	Description string
+	Apply       func() error
+}
+
+// Plan compares the manifest against the YERD configuration and returns
+// the changes required to converge, in the order they must be applied
+func (m *Manifest) Plan() ([]Change, error) {
+	if err := m.checkPhpAvailable(); err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	changes = append(changes, m.planPhp()...)
+	changes = append(changes, m.planCli()...)
+	changes = append(changes, m.planComposer()...)
+	changes = append(changes, m.planWeb()...)
+	changes = append(changes, m.planSites()...)
+
+	return changes, nil
+}
+
+// checkPhpAvailable ensures every PHP version used by the CLI or a site
+// is either installed already or will be installed by the manifest
+func (m *Manifest) checkPhpAvailable() error {
+	required := []string{}
+	if m.Cli != "" {
+		required = append(required, m.Cli)
+	}
+
+	for _, site := range m.Sites {
+		if site.Php != "" {
+			required = append(required, site.Php)
+		}
+	}
+
+	for _, version := range required {
+		if _, listed := m.Php[version]; listed {
+			continue
+		}
+
+		if _, installed := config.GetInstalledPhpInfo(version); !installed {
+			return fmt.Errorf("php %s is used but is neither installed nor listed under php", version)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manifest) planPhp() []Change {
+	changes := []Change{}
+
+	for _, version := range m.PhpVersions() {
+		extensions := m.Php[version].Extensions
+
+		info, installed := config.GetInstalledPhpInfo(version)
+		if !installed {
+			if len(extensions) == 0 {
+				extensions = constants.GetDefaultExtensions()
+			}
+
+			changes = append(changes, Change{
+				Description: fmt.Sprintf("Install PHP %s", version),
+				Apply:       func() error { return installPhp(version, extensions) },
+			})
+			continue
+		}
+
+		if len(extensions) == 0 {
+			continue
+		}
+
+		toAdd := utils.RemoveItems(extensions, info.Extensions...)
+		toRemove := utils.RemoveItems(info.Extensions, extensions...)
+		if len(toAdd) == 0 && len(toRemove) == 0 {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Description: describeExtensions(version, toAdd, toRemove),
+			Apply:       func() error { return rebuildPhp(info, toAdd, toRemove) },
+		})
+	}
+
+	return changes
+}
+
+func (m *Manifest) planCli() []Change {
+	if m.Cli == "" {
+		return nil
+	}
+
+	if info, installed := config.GetInstalledPhpInfo(m.Cli); installed && info.IsCLI {
+		return nil
+	}
+
+	return []Change{{
+		Description: fmt.Sprintf("Set PHP %s as the default CLI version", m.Cli),
+		Apply: func() error {
+			info, installed := config.GetInstalledPhpInfo(m.Cli)
+			if !installed {
+				return fmt.Errorf("php %s is not installed", m.Cli)
+			}
+			return phpinstaller.SetCliVersion(info)
+		},
+	}}
+}
+
+func (m *Manifest) planComposer() []Change {
+	if !m.Composer || utils.FileExists(constants.LocalComposerPath) {
+		return nil
+	}
+
+	return []Change{{
+		Description: "Install Composer",
+		Apply:       composer.InstallComposer,
+	}}
+}
+
+// planWeb installs the web components when requested, or when the
+// manifest has sites which need them
+func (m *Manifest) planWeb() []Change {
+	if (!m.Web && len(m.Sites) == 0) || config.GetWebConfig().Installed {
+		return nil
+	}
+
+	return []Change{{
+		Description: "Install the web components",
+		Apply: func() error {
+			installer, err := nginx.NewNginxInstaller(false, true)
+			if err != nil {
+				return err
+			}
+			return installer.Install()
+		},
+	}}
+}
+
+func (m *Manifest) planSites() []Change {
+	webConfig := config.GetWebConfig()
+	changes := []Change{}
+
+	for _, site := range m.Sites {
+		existing, found := findSite(webConfig, site)
+		if !found {
+			changes = append(changes, Change{
+				Description: fmt.Sprintf("Add site %s (%s)", site.Domain, site.Directory),
+				Apply:       func() error { return addSite(site) },
+			})
+			continue
+		}
+
+		changes = append(changes, planSite(existing, site)...)
+	}
+
+	return changes
+}
+
+// planSite returns a change for every setting of an existing site which
+// differs from the manifest, the domain is changed first so the later
+// changes can identify the site by its new domain
+func planSite(existing config.SiteConfig, site SiteManifest) []Change {
+	changes := []Change{}
+	set := func(name, value, identifier string) {
+		description := fmt.Sprintf("Set %s of %s to %s", name, site.Domain, value)
+		if value == "" {
+			description = fmt.Sprintf("Clear %s of %s", name, site.Domain)
+		}
+
+		changes = append(changes, Change{
+			Description: description,
+			Apply:       func() error { return setSiteValue(name, value, identifier) },
+		})
+	}
+
+	if existing.Domain != site.Domain {
+		set("domain", site.Domain, existing.Domain)
+	}
+
+	if existing.RootDirectory != site.Directory {
+		set("directory", site.Directory, site.Domain)
+	}
+
+	if site.Public != "" && existing.PublicDirectory != site.Public {
+		set("public", site.Public, site.Domain)
+	}
+
+	if site.Php != "" && existing.PhpVersion != site.Php {
+		set("php", site.Php, site.Domain)
+	}
+
+	if site.Aliases != nil && !sameItems(existing.Aliases, site.Aliases) {
+		set("aliases", strings.Join(site.Aliases, ","), site.Domain)
+	}
+
+	if site.Secure != nil && existing.Insecure == *site.Secure {
+		set("secure", strconv.FormatBool(*site.Secure), site.Domain)
+	}
+
+	return changes
+}
+
+// findSite returns the configured site with the manifest site's domain,
+// falling back to the site registered for its directory
+func findSite(webConfig *config.WebConfig, site SiteManifest) (config.SiteConfig, bool) {
+	if existing, found := webConfig.Sites[site.Domain]; found {
+		return existing, true
+	}
+
+	for _, existing := range webConfig.Sites {
+		if existing.RootDirectory == site.Directory {
+			return existing, true
+		}
+	}
+
+	return config.SiteConfig{}, false
+}
+
+func installPhp(version string, extensions []string) error {
+	installer, err := phpinstaller.NewPhpInstaller(version, false, true)
+	if err != nil {
+		return err
+	}
+
+	installer.UseExtensions(extensions)
+	return installer.Install()
+}
+
+// rebuildPhp queues the extension changes the same way 'yerd php
+// extensions' does, then rebuilds PHP to apply them
+func rebuildPhp(info *config.PhpInfo, toAdd, toRemove []string) error {
+	info.AddExtensions = toAdd
+	info.RemoveExtensions = toRemove
+
+	if err := config.SetStruct(fmt.Sprintf("php.[%s]", info.Version), info); err != nil {
+		return err
+	}
+
+	return phpinstaller.RunRebuild(info, false, false)
+}
+
+func addSite(site SiteManifest) error {
+	siteManager, err := manager.NewSiteManager()
+	if err != nil {
+		return err
+	}
+
+	siteManager.Aliases = site.Aliases
+	if site.Secure != nil {
+		siteManager.Insecure = !*site.Secure
+	}
+
+	return siteManager.AddSite(site.Directory, site.Domain, site.Public, site.Php)
+}
+
+// setSiteValue uses a new site manager for every change, so each one
+// sees the configuration written by the change before it
+func setSiteValue(name, value, identifier string) error {
+	siteManager, err := manager.NewSiteManager()
+	if err != nil {
+		return err
+	}
+
+	return siteManager.SetValue(name, value, identifier)
+}
+
+func describeExtensions(version string, toAdd, toRemove []string) string {
+	parts := []string{}
+	if len(toAdd) > 0 {
+		parts = append(parts, "add "+strings.Join(toAdd, ", "))
+	}
+
+	if len(toRemove) > 0 {
+		parts = append(parts, "remove "+strings.Join(toRemove, ", "))
+	}
+
+	return fmt.Sprintf("Rebuild PHP %s to %s", version, strings.Join(parts, " and "))
+}
+
+func sameItems(a, b []string) bool {
+	a = slices.Clone(a)
+	b = slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,152 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/constants"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes a complete development environment which can be
+// committed alongside a project and reproduced with 'yerd apply'
+type Manifest struct {
+	Php      map[string]PhpManifest `yaml:"php"`
+	Cli      string                 `yaml:"cli"`
+	Composer bool                   `yaml:"composer"`
+	Web      bool                   `yaml:"web"`
+	Sites    []SiteManifest         `yaml:"sites"`
+	path     string
+}
+
+// PhpManifest is a single PHP version, Extensions is the complete set
+// of extensions, when empty the version is left with its current set
+type PhpManifest struct {
+	Extensions []string `yaml:"extensions"`
+}
+
+// SiteManifest is a single site, Directory is relative to the manifest
+// and every other field is optional, unset fields are left unchanged
+// on existing sites
+type SiteManifest struct {
+	Directory string   `yaml:"directory"`
+	Domain    string   `yaml:"domain"`
+	Php       string   `yaml:"php"`
+	Public    string   `yaml:"public"`
+	Aliases   []string `yaml:"aliases"`
+	Secure    *bool    `yaml:"secure"`
+}
+
+// Load reads and validates the manifest at path
+func Load(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read manifest: %w", err)
+	}
+
+	manifest := &Manifest{}
+	if err := yaml.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest: %w", err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.path = abs
+	manifest.normalise()
+
+	if err := manifest.validate(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// PhpVersions returns the PHP versions in the manifest, oldest first
+func (m *Manifest) PhpVersions() []string {
+	versions := []string{}
+	for version := range m.Php {
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return slices.Index(constants.GetAvailablePhpVersions(), versions[i]) <
+			slices.Index(constants.GetAvailablePhpVersions(), versions[j])
+	})
+
+	return versions
+}
+
+// normalise resolves site directories against the manifest location and
+// fills in the default domain the same way 'yerd sites add' does
+func (m *Manifest) normalise() {
+	base := filepath.Dir(m.path)
+
+	for i := range m.Sites {
+		site := &m.Sites[i]
+		if site.Directory == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(site.Directory) {
+			site.Directory = filepath.Join(base, site.Directory)
+		}
+		site.Directory = filepath.Clean(site.Directory)
+
+		if site.Domain == "" {
+			site.Domain = filepath.Base(site.Directory) + ".test"
+		}
+		site.Domain = strings.ToLower(site.Domain)
+
+		for j, alias := range site.Aliases {
+			site.Aliases[j] = strings.ToLower(strings.TrimSpace(alias))
+		}
+
+		if site.Public != "" {
+			site.Public = strings.Trim(filepath.Clean("/"+site.Public), "/")
+		}
+	}
+}
+
+func (m *Manifest) validate() error {
+	for version, php := range m.Php {
+		if !constants.IsValidPhpVersion(version) {
+			return fmt.Errorf("php %s is not supported, expected one of %s",
+				version, strings.Join(constants.GetAvailablePhpVersions(), ", "))
+		}
+
+		if _, invalid := constants.ValidateExtensions(php.Extensions); len(invalid) > 0 {
+			return fmt.Errorf("php %s has unknown extensions: %s", version, strings.Join(invalid, ", "))
+		}
+	}
+
+	if m.Cli != "" && !constants.IsValidPhpVersion(m.Cli) {
+		return fmt.Errorf("cli php %s is not supported", m.Cli)
+	}
+
+	domains := []string{}
+	for _, site := range m.Sites {
+		if site.Directory == "" {
+			return fmt.Errorf("every site requires a directory")
+		}
+
+		if site.Php != "" && !constants.IsValidPhpVersion(site.Php) {
+			return fmt.Errorf("site %s uses unsupported php %s", site.Domain, site.Php)
+		}
+
+		for _, hostname := range append([]string{site.Domain}, site.Aliases...) {
+			if slices.Contains(domains, hostname) {
+				return fmt.Errorf("%s is used by more than one site", hostname)
+			}
+			domains = append(domains, hostname)
+		}
+	}
+
+	return nil
+}
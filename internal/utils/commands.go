@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+
+	"github.com/lumosolutions/yerd/internal/constants"
 )
 
 // CommandExistschecks if a command exists using the 'which' command
@@ -14,6 +16,23 @@ func CommandExists(cmd string) (string, bool) {
 	return strings.Trim(output, "\n"), success
 }
 
+// IsConflictingCommand checks whether a command on the PATH resolves to an
+// executable which is not managed by YERD, eg: a distro installed php
+func IsConflictingCommand(command string) bool {
+	if path, exists := CommandExists(command); exists {
+		if IsSymlink(path) {
+			rootPath, err := ReadSymlink(path)
+			LogInfo("php", "SymLink for '%s' at '%s' is '%s'", command, path, rootPath)
+			return err != nil || !strings.HasPrefix(rootPath, constants.YerdBaseDir)
+		} else {
+			LogInfo("php", "'%s' at '%s' is not a symlink", command, path)
+			return !strings.HasPrefix(path, constants.YerdBaseDir)
+		}
+	}
+
+	return false
+}
+
 // ExecuteCommand runs a command with full output logging to the specified logger.
 // command: Command to run
 // args: Additonal arguments
@@ -15,6 +15,7 @@ func EnsureYerdDirectories() error {
 		constants.YerdPHPDir,
 		constants.YerdEtcDir,
 		constants.YerdWebDir,
+		constants.YerdCacheDir,
 	}
 
 	for _, dir := range dirs {
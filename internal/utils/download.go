@@ -13,7 +13,6 @@ import (
 const (
 	DefaultTimeout   = 30 * time.Second
 	DefaultUserAgent = "YERD/1.0"
-	ConfigRepoBase   = "https://raw.githubusercontent.com/LumoSolutions/yerd"
 )
 
 type DownloadOptions struct {
@@ -38,6 +37,11 @@ func DownloadFile(url, filePath string, opts *DownloadOptions) error {
 		opts = DefaultDownloadOptions()
 	}
 
+	if IsOffline() {
+		return fmt.Errorf("offline and %s is not in the source cache", url)
+	}
+
+	url = MirrorURL(url)
 	LogInfo(context, "Starting download from %s", url)
 	LogInfo(context, "Destination: %s", filePath)
 
@@ -62,9 +66,25 @@ func DownloadFile(url, filePath string, opts *DownloadOptions) error {
 }
 
 func useHttpClient(url, filePath string, opts *DownloadOptions) error {
+	body, err := httpGet(url, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteToFile(filePath, body, constants.FilePermissions); err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+
+	LogInfo(context, "Downloaded using HTTP client")
+	return nil
+}
+
+// httpGet returns the body of a successful GET request to url
+func httpGet(url string, opts *DownloadOptions) ([]byte, error) {
 	client := &http.Client{
 		Timeout: opts.Timeout,
 		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
 			DisableKeepAlives: true,
 		},
 	}
@@ -72,7 +92,7 @@ func useHttpClient(url, filePath string, opts *DownloadOptions) error {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		LogError(err, context)
-		return fmt.Errorf("failed to create request")
+		return nil, fmt.Errorf("failed to create request")
 	}
 
 	req.Header.Set("User-Agent", opts.UserAgent)
@@ -82,25 +102,20 @@ func useHttpClient(url, filePath string, opts *DownloadOptions) error {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %v", err)
+		return nil, fmt.Errorf("HTTP request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP request failed with status %d", resp.StatusCode)
+		return nil, fmt.Errorf("HTTP request failed with status %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %v", err)
 	}
 
-	if err := WriteToFile(filePath, body, constants.FilePermissions); err != nil {
-		return fmt.Errorf("failed to create file: %v", err)
-	}
-
-	LogInfo(context, "Downloaded using HTTP client")
-	return nil
+	return body, nil
 }
 
 func useCommandLine(url, filePath string) error {
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Exit codes returned by every command, scripts can rely on these
+const (
+	ExitOK                  = 0
+	ExitFailure             = 1
+	ExitUsage               = 2
+	ExitPermission          = 3
+	ExitNotInstalled        = 4
+	ExitInteractionRequired = 5
+)
+
+// ErrInteractionRequired is returned instead of prompting the user when
+// running in non-interactive mode
+var ErrInteractionRequired = errors.New("user input is required but YERD is running non-interactively")
+
+// ExitError carries the exit code of a failed command, Err is nil when
+// the failure has already been reported to the user
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Exit returns an error which makes the command exit with code, for
+// failures which have already been printed
+func Exit(code int) error {
+	return &ExitError{Code: code}
+}
+
+// ExitWithError returns an error which makes the command exit with
+// code after printing err
+func ExitWithError(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns the exit code for an error returned by a command,
+// errors which do not carry a code come from argument and flag parsing
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	if errors.Is(err, ErrInteractionRequired) {
+		return ExitInteractionRequired
+	}
+
+	return ExitUsage
+}
+
+// ShouldReportError checks whether err still needs to be printed
+func ShouldReportError(err error) bool {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Err != nil
+	}
+
+	return err != nil
+}
+
+var nonInteractive = isTruthy(os.Getenv("YERD_NONINTERACTIVE"))
+
+// SetNonInteractive enables non-interactive mode, which is also enabled
+// by setting YERD_NONINTERACTIVE, prompts fail instead of waiting for
+// input and progress is written as plain lines without colour
+func SetNonInteractive(enabled bool) {
+	if enabled {
+		nonInteractive = true
+	}
+
+	if nonInteractive {
+		color.NoColor = true
+	}
+}
+
+// IsNonInteractive reports whether YERD must not prompt for input
+func IsNonInteractive() bool {
+	return nonInteractive
+}
+
+// Confirm asks a yes or no question, returning ErrInteractionRequired
+// in non-interactive mode so the caller can fail instead of blocking
+func Confirm(question string) (bool, error) {
+	if nonInteractive {
+		return false, ErrInteractionRequired
+	}
+
+	fmt.Printf("%s (y/N): ", question)
+
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(strings.TrimSpace(response))
+
+	return response == "y" || response == "yes", nil
+}
+
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+
+	return false
+}
+
+// Reported converts an error which has already been shown to the user,
+// usually by a spinner, into a silent failure of the command
+func Reported(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrInteractionRequired) {
+		return Exit(ExitInteractionRequired)
+	}
+
+	return Exit(ExitFailure)
+}
@@ -2,14 +2,10 @@ package utils
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/lumosolutions/yerd/internal/constants"
-	"github.com/lumosolutions/yerd/internal/version"
 )
 
 // FileExists checks if a file or directory exists at the given path.
@@ -194,7 +190,7 @@ func ReadSymlink(path string) (string, error) {
 // CreateSymlink creates a symbolic link, removing existing link if present.
 // target: Path to link target, link: Path where symlink should be created. Returns error if creation fails.
 func CreateSymlink(target, link string) error {
-	if FileExists(link) {
+	if FileExists(link) || IsSymlink(link) {
 		if err := os.Remove(link); err != nil {
 			LogError(err, "create-symlink")
 			return fmt.Errorf("failed to remove existing symlink %s: %v", link, err)
@@ -241,64 +237,6 @@ func ExtractArchive(archivePath, toFolder string, userCtx *UserContext) error {
 	return nil
 }
 
-// FetchFromGitHub downloads a file from github and returns it as
-// a string value
-func FetchFromGitHub(folder, file string) (string, error) {
-	filePath := filepath.Join(".config", folder, file)
-
-	url := fmt.Sprintf(
-		"https://raw.githubusercontent.com/%s/%s/%s",
-		version.GetRepo(),
-		version.GetBranch(),
-		filePath,
-	)
-
-	LogInfo("github", "Attempting to download %s", url)
-
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			DisableKeepAlives: true,
-		},
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		LogError(err, "github")
-		LogInfo("github", "Failed to create request")
-		return "", fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("User-Agent", "YERD/1.0")
-	req.Header.Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	req.Header.Set("Pragma", "no-cache")
-	req.Header.Set("Expires", "0")
-
-	resp, err := client.Do(req)
-
-	if err != nil {
-		LogError(err, "github")
-		LogInfo("github", "Failed to fetch data from github")
-		return "", fmt.Errorf("failed to fetch from GitHub: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check if the request was successful
-	if resp.StatusCode != http.StatusOK {
-		LogInfo("github", "Status code was %d which was not expected", resp.StatusCode)
-		return "", fmt.Errorf("GitHub returned status %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	// Read the response body
-	content, err := io.ReadAll(resp.Body)
-	if err != nil {
-		LogInfo("github", "Failed to read body")
-		return "", fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	return string(content), nil
-}
-
 // RemoveFile removes a single file
 func RemoveFile(filePath string) error {
 	info, err := os.Stat(filePath)
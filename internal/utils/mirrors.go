@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"os"
+	"strings"
+)
+
+// Default locations sources are downloaded from, each can be replaced
+// by a mirror such as a corporate proxy or a local HTTP stand-in
+const (
+	PhpSourceBase      = "https://www.php.net"
+	NginxSourceBase    = "https://nginx.org"
+	ComposerSourceBase = "https://getcomposer.org"
+)
+
+// Mirrors overrides the base URL of each upstream source, an empty value
+// uses the default, Offline serves everything from the source cache
+type Mirrors struct {
+	Php      string `json:"php,omitempty" yaml:"php,omitempty"`
+	Nginx    string `json:"nginx,omitempty" yaml:"nginx,omitempty"`
+	Composer string `json:"composer,omitempty" yaml:"composer,omitempty"`
+	Offline  bool   `json:"offline,omitempty" yaml:"offline,omitempty"`
+}
+
+var mirrors = mirrorsFromEnv()
+
+// mirrorsFromEnv reads the YERD_MIRROR_* and YERD_OFFLINE variables
+func mirrorsFromEnv() Mirrors {
+	return Mirrors{
+		Php:      os.Getenv("YERD_MIRROR_PHP"),
+		Nginx:    os.Getenv("YERD_MIRROR_NGINX"),
+		Composer: os.Getenv("YERD_MIRROR_COMPOSER"),
+		Offline:  isTruthy(os.Getenv("YERD_OFFLINE")),
+	}
+}
+
+// SetMirrors applies the configured mirrors, environment variables take
+// precedence over configuration so a single run can be redirected
+func SetMirrors(configured Mirrors) {
+	env := mirrorsFromEnv()
+
+	mirrors = configured
+	if env.Php != "" {
+		mirrors.Php = env.Php
+	}
+	if env.Nginx != "" {
+		mirrors.Nginx = env.Nginx
+	}
+	if env.Composer != "" {
+		mirrors.Composer = env.Composer
+	}
+	if env.Offline {
+		mirrors.Offline = true
+	}
+}
+
+// GetMirrors returns the mirrors in use for this run
+func GetMirrors() Mirrors {
+	return mirrors
+}
+
+// IsOffline reports whether sources must only come from the source cache
+func IsOffline() bool {
+	return mirrors.Offline
+}
+
+// MirrorURL rewrites a URL on one of the default source bases to the
+// configured mirror, other URLs are returned unchanged
+func MirrorURL(url string) string {
+	replacements := map[string]string{
+		PhpSourceBase:      mirrors.Php,
+		NginxSourceBase:    mirrors.Nginx,
+		ComposerSourceBase: mirrors.Composer,
+	}
+
+	for base, mirror := range replacements {
+		if mirror == "" || !strings.HasPrefix(url, base+"/") {
+			continue
+		}
+
+		return strings.TrimRight(mirror, "/") + strings.TrimPrefix(url, base)
+	}
+
+	return url
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+var outputFormat = OutputText
+
+// SetOutputFormat selects how list and status commands print their
+// results, format is one of text, json or yaml
+func SetOutputFormat(format string) error {
+	switch strings.ToLower(format) {
+	case "", OutputText:
+		outputFormat = OutputText
+	case OutputJSON:
+		outputFormat = OutputJSON
+	case OutputYAML, "yml":
+		outputFormat = OutputYAML
+	default:
+		return fmt.Errorf("unknown output format '%s', expected text, json or yaml", format)
+	}
+
+	return nil
+}
+
+// IsStructuredOutput reports whether results should be printed as
+// json or yaml instead of human readable text
+func IsStructuredOutput() bool {
+	return outputFormat != OutputText
+}
+
+// PrintStructured writes value to stdout in the selected output format
+func PrintStructured(value any) error {
+	switch outputFormat {
+	case OutputYAML:
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(value)
+	default:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+}
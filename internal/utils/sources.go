@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lumosolutions/yerd/internal/constants"
+)
+
+// sourceCachePath returns where the copy of url is kept in the source
+// cache, keyed on the default URL so changing mirror keeps the cache
+func sourceCachePath(url string) string {
+	key := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	key = strings.NewReplacer("?", "_", "&", "_", "=", "_", "..", "_").Replace(key)
+
+	return filepath.Join(constants.SourceCacheDir, filepath.FromSlash(key))
+}
+
+// keepSource stores a copy of a download in the source cache, failing
+// to do so is only logged as the download itself succeeded
+func keepSource(url string, content []byte) {
+	if err := WriteToFile(sourceCachePath(url), content, constants.FilePermissions); err != nil {
+		LogWarning("sources", "Unable to cache %s: %v", url, err)
+	}
+}
+
+// DownloadSource downloads a versioned source archive, such as a PHP or
+// nginx tarball, which never changes once published, so a copy in the
+// source cache is used instead of downloading it again
+func DownloadSource(url, filePath string) error {
+	cached := sourceCachePath(url)
+	if FileExists(cached) {
+		LogInfo("sources", "Using cached copy of %s", url)
+		if err := CreateDirectory(filepath.Dir(filePath)); err != nil {
+			return err
+		}
+
+		return Copy(cached, filePath)
+	}
+
+	if err := DownloadFile(url, filePath, nil); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err == nil {
+		keepSource(url, content)
+	}
+
+	return nil
+}
+
+// ForgetSource removes a cached source, used when it fails verification
+// so the next attempt downloads it again
+func ForgetSource(url string) {
+	os.Remove(sourceCachePath(url))
+}
+
+// FetchSource returns the content of a source which changes over time,
+// such as a release listing or composer.phar, the last copy fetched is used
+// when offline or when the fetch fails
+func FetchSource(url string) ([]byte, error) {
+	cached := sourceCachePath(url)
+
+	if !IsOffline() {
+		content, err := httpGet(MirrorURL(url), DefaultDownloadOptions())
+		if err == nil {
+			keepSource(url, content)
+			return content, nil
+		}
+
+		if !FileExists(cached) {
+			return nil, err
+		}
+
+		LogWarning("sources", "Unable to fetch %s, using cached copy: %v", url, err)
+	}
+
+	content, err := os.ReadFile(cached)
+	if err != nil {
+		return nil, fmt.Errorf("offline and %s is not in the source cache", url)
+	}
+
+	return content, nil
+}
+
+// ClearSourceCache removes every cached source
+func ClearSourceCache() error {
+	return RemoveFolder(constants.SourceCacheDir)
+}
@@ -22,6 +22,7 @@ type Spinner struct {
 	doneChan   chan struct{}
 	hideCursor bool
 	delay      time.Duration
+	plain      bool
 }
 
 // New creates a new spinner instance
@@ -66,6 +67,15 @@ func (s *Spinner) Start() {
 		return
 	}
 	s.active = true
+	s.plain = IsNonInteractive()
+
+	// Non-interactive runs are usually logged, so progress is written
+	// as plain lines instead of being redrawn in place
+	if s.plain {
+		fmt.Fprintf(s.writer, "%s\n", s.phrase)
+		s.mu.Unlock()
+		return
+	}
 	s.mu.Unlock()
 
 	if s.hideCursor {
@@ -113,6 +123,14 @@ func (s *Spinner) UpdatePhrase(phrase string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.active && s.plain {
+		if phrase != s.phrase {
+			fmt.Fprintf(s.writer, "%s\n", phrase)
+		}
+		s.phrase = phrase
+		return
+	}
+
 	s.phrase = phrase
 
 	if s.active {
@@ -150,8 +168,8 @@ func (s *Spinner) addStatus(status string, outputColor *color.Color) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if !s.active {
-		// If spinner isn't active, just print the status
+	if !s.active || s.plain {
+		// If spinner isn't animating, just print the status
 		outputColor.Fprintf(s.writer, "%s\n", status)
 		return
 	}
@@ -174,6 +192,14 @@ func (s *Spinner) Stop(finalMessage string, outputColor *color.Color) {
 		return
 	}
 	s.active = false
+
+	if s.plain {
+		if finalMessage != "" {
+			outputColor.Fprintf(s.writer, "%s\n", finalMessage)
+		}
+		s.mu.Unlock()
+		return
+	}
 	s.mu.Unlock()
 
 	close(s.stopChan)
@@ -53,3 +53,23 @@ func SystemdServiceActive(service string) bool {
 	_, success := ExecuteCommand("systemctl", "is-active", service)
 	return success
 }
+
+func SystemdReloadService(service string) error {
+	if output, success := ExecuteCommand("systemctl", "reload", service); !success {
+		LogInfo("systemd", "Failed to reload service")
+		LogInfo("systemd", "Output: %s", output)
+		return fmt.Errorf("unable to reload systemd service %s", service)
+	}
+
+	return nil
+}
+
+func SystemdRestartService(service string) error {
+	if output, success := ExecuteCommand("systemctl", "restart", service); !success {
+		LogInfo("systemd", "Failed to restart service")
+		LogInfo("systemd", "Output: %s", output)
+		return fmt.Errorf("unable to restart systemd service %s", service)
+	}
+
+	return nil
+}
@@ -2,26 +2,110 @@ package utils
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/lumosolutions/yerd/internal/constants"
+)
+
+const (
+	templateLeftDelim  = "{{%"
+	templateRightDelim = "%}}"
 )
 
-// TemplateData represents key-value pairs for template replacement
-type TemplateData map[string]string
+// TemplateData holds the values available to a template, values are
+// usually strings but booleans and lists can be used in conditions and loops
+type TemplateData map[string]any
+
+var templateKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// templateBuiltins are the functions provided by text/template, they are
+// never treated as keys which must be present in the data
+var templateBuiltins = []string{
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or",
+	"print", "printf", "println", "urlquery", "eq", "ge", "gt", "le", "lt", "ne",
+}
+
+// templateKeywords are the actions and literals of text/template, a key
+// with one of these names could never be used
+var templateKeywords = []string{
+	"block", "break", "continue", "define", "else", "end", "if", "range",
+	"template", "with", "nil", "true", "false",
+}
+
+// templateHelpers are the helper functions available to every template
+var templateHelpers = template.FuncMap{
+	"join": func(separator string, values []string) string {
+		return strings.Join(values, separator)
+	},
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+	"replace": func(old, new, value string) string { return strings.ReplaceAll(value, old, new) },
+	"indent": func(spaces int, value string) string {
+		padding := strings.Repeat(" ", spaces)
+		lines := strings.Split(value, "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = padding + line
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+	"default": func(fallback, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	"versionAtLeast": func(version, minimum string) bool {
+		return constants.ComparePhpVersions(version, minimum) >= 0
+	},
+}
+
+// Template renders a template using {{% %}} delimiters, each key in data
+// can be used bare, eg: {{% domain %}}, in conditions and loops such as
+// {{% if secure %}} or {{% range aliases %}}, and with the helper
+// functions, eg: {{% join " " aliases %}}. An error is returned when the
+// template uses a key which is missing from data
+func Template(content string, data TemplateData) (string, error) {
+	if err := ValidateTemplate(content, data); err != nil {
+		return "", err
+	}
 
-// Template processes a template string by replacing {{% key %}} patterns with values
-func Template(template string, data TemplateData) string {
-	result := template
+	funcs := template.FuncMap{}
+	for name, helper := range templateHelpers {
+		funcs[name] = helper
+	}
 
 	for key, value := range data {
-		placeholder := fmt.Sprintf("{{%% %s %%}}", key)
-		result = strings.ReplaceAll(result, placeholder, value)
+		if templateKeyPattern.MatchString(key) {
+			funcs[key] = func() any { return value }
+		}
+	}
+
+	tmpl, err := template.New("template").
+		Delims(templateLeftDelim, templateRightDelim).
+		Option("missingkey=error").
+		Funcs(funcs).
+		Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
 	}
 
-	return result
+	var output strings.Builder
+	if err := tmpl.Execute(&output, map[string]any(data)); err != nil {
+		return "", fmt.Errorf("unable to render template: %w", err)
+	}
+
+	return output.String(), nil
 }
 
 // TemplateWithDefaults processes a template with fallback values for missing keys
-func TemplateWithDefaults(template string, data TemplateData, defaults TemplateData) string {
+func TemplateWithDefaults(content string, data TemplateData, defaults TemplateData) (string, error) {
 	merged := make(TemplateData)
 
 	for k, v := range defaults {
@@ -32,75 +116,119 @@ func TemplateWithDefaults(template string, data TemplateData, defaults TemplateD
 		merged[k] = v
 	}
 
-	return Template(template, merged)
+	return Template(content, merged)
 }
 
-// ExtractTemplateKeys returns all unique template keys found in a template string
-func ExtractTemplateKeys(template string) []string {
-	uniqueKeys := extractUniqueKeys(template)
-	return toSlice(uniqueKeys)
-}
+// ExtractTemplateKeys returns all unique keys used by a template, either
+// bare or as fields of the data, helpers and variables are not included
+func ExtractTemplateKeys(content string) []string {
+	tree := parse.New("template")
+	tree.Mode = parse.SkipFuncCheck
+
+	if _, err := tree.Parse(content, templateLeftDelim, templateRightDelim, map[string]*parse.Tree{}); err != nil {
+		LogDebug("template", "Unable to parse template: %v", err)
+		return []string{}
+	}
 
-// extractUniqueKeys finds all unique keys in the template
-func extractUniqueKeys(template string) map[string]bool {
 	uniqueKeys := make(map[string]bool)
-	remaining := template
+	collectKeys(tree.Root, uniqueKeys, true)
 
-	for {
-		key, nextPos := findNextKey(remaining)
-		if key == "" {
-			break
-		}
-		uniqueKeys[key] = true
-		remaining = remaining[nextPos:]
+	keys := make([]string, 0, len(uniqueKeys))
+	for key := range uniqueKeys {
+		keys = append(keys, key)
 	}
+	slices.Sort(keys)
 
-	return uniqueKeys
+	return keys
 }
 
-// findNextKey finds the next template key and returns it along with the position after it
-func findNextKey(template string) (string, int) {
-	const startDelim = "{{%"
-	const endDelim = "%}}"
-
-	startIdx := strings.Index(template, startDelim)
-	if startIdx == -1 {
-		return "", 0
-	}
-
-	afterStart := startIdx + len(startDelim)
-	endIdx := strings.Index(template[afterStart:], endDelim)
-	if endIdx == -1 {
-		return "", 0
+// collectKeys walks a parsed template adding every key it references,
+// fields only refer to the data while dot has not been changed by a
+// range or with
+func collectKeys(node parse.Node, keys map[string]bool, dotIsData bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectKeys(child, keys, dotIsData)
+		}
+	case *parse.ActionNode:
+		collectKeys(n.Pipe, keys, dotIsData)
+	case *parse.IfNode:
+		collectBranchKeys(&n.BranchNode, keys, dotIsData, dotIsData)
+	case *parse.RangeNode:
+		collectBranchKeys(&n.BranchNode, keys, dotIsData, false)
+	case *parse.WithNode:
+		collectBranchKeys(&n.BranchNode, keys, dotIsData, false)
+	case *parse.TemplateNode:
+		collectKeys(n.Pipe, keys, dotIsData)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectKeys(cmd, keys, dotIsData)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectKeys(arg, keys, dotIsData)
+		}
+	case *parse.ChainNode:
+		collectKeys(n.Node, keys, dotIsData)
+	case *parse.FieldNode:
+		if dotIsData {
+			keys[n.Ident[0]] = true
+		}
+	case *parse.IdentifierNode:
+		if !isReservedTemplateName(n.Ident) {
+			keys[n.Ident] = true
+		}
 	}
+}
 
-	key := strings.TrimSpace(template[afterStart : afterStart+endIdx])
-	nextPos := afterStart + endIdx + len(endDelim)
+// collectBranchKeys adds the keys referenced by an if, range or with,
+// bodyDotIsData applies to the body, dotIsData to the pipe and else
+func collectBranchKeys(branch *parse.BranchNode, keys map[string]bool, dotIsData, bodyDotIsData bool) {
+	collectKeys(branch.Pipe, keys, dotIsData)
+	collectKeys(branch.List, keys, bodyDotIsData)
+	collectKeys(branch.ElseList, keys, dotIsData)
+}
 
-	return key, nextPos
+// isReservedTemplateName reports whether name is a helper, builtin or
+// keyword, which a key of the same name would replace or be hidden by
+func isReservedTemplateName(name string) bool {
+	_, helper := templateHelpers[name]
+	return helper || slices.Contains(templateBuiltins, name) || slices.Contains(templateKeywords, name)
 }
 
-// toSlice converts a map of keys to a slice
-func toSlice(uniqueKeys map[string]bool) []string {
-	keys := make([]string, 0, len(uniqueKeys))
-	for key := range uniqueKeys {
-		if key != "" {
-			keys = append(keys, key)
+// ValidateTemplate checks that data can render content, a key may not
+// share its name with a helper, builtin or keyword and every key used by
+// the template must have a value
+func ValidateTemplate(content string, data TemplateData) error {
+	var reserved []string
+	for key := range data {
+		if isReservedTemplateName(key) {
+			reserved = append(reserved, key)
 		}
 	}
-	return keys
-}
 
-// ValidateTemplate checks if all template keys have corresponding data values
-func ValidateTemplate(template string, data TemplateData) []string {
-	keys := ExtractTemplateKeys(template)
-	var missing []string
+	if len(reserved) > 0 {
+		slices.Sort(reserved)
+		return fmt.Errorf("template values %s use the names of template functions", strings.Join(reserved, ", "))
+	}
 
-	for _, key := range keys {
+	var missing []string
+	for _, key := range ExtractTemplateKeys(content) {
 		if _, exists := data[key]; !exists {
 			missing = append(missing, key)
 		}
 	}
 
-	return missing
+	if len(missing) > 0 {
+		return fmt.Errorf("template is missing values for %s", strings.Join(missing, ", "))
+	}
+
+	return nil
 }
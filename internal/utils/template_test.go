@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		data     TemplateData
+		expected string
+	}{
+		{
+			name:     "bare key",
+			content:  "server_name {{% domain %}};",
+			data:     TemplateData{"domain": "shop.test"},
+			expected: "server_name shop.test;",
+		},
+		{
+			name:     "field",
+			content:  "root {{% .root %}};",
+			data:     TemplateData{"root": "/srv/shop"},
+			expected: "root /srv/shop;",
+		},
+		{
+			name:     "double braces are left alone",
+			content:  "{{ domain }} {{% domain %}}",
+			data:     TemplateData{"domain": "shop.test"},
+			expected: "{{ domain }} shop.test",
+		},
+		{
+			name:     "condition",
+			content:  "{{% if secure %}}listen 443 ssl;{{% else %}}listen 80;{{% end %}}",
+			data:     TemplateData{"secure": false},
+			expected: "listen 80;",
+		},
+		{
+			name:     "range",
+			content:  "{{% range aliases %}}[{{% . %}}]{{% end %}}",
+			data:     TemplateData{"aliases": []string{"a.test", "b.test"}},
+			expected: "[a.test][b.test]",
+		},
+		{
+			name:     "helpers",
+			content:  `{{% join " " aliases | upper %}} {{% default "8.4" php %}}`,
+			data:     TemplateData{"aliases": []string{"a.test", "b.test"}, "php": ""},
+			expected: "A.TEST B.TEST 8.4",
+		},
+		{
+			name:     "trimmed markers",
+			content:  "a\n{{%- if secure -%}}\nb\n{{%- end -%}}\nc",
+			data:     TemplateData{"secure": true},
+			expected: "abc",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := Template(test.content, test.data)
+			if err != nil {
+				t.Fatalf("Template: %v", err)
+			}
+
+			if output != test.expected {
+				t.Errorf("Template = %q, want %q", output, test.expected)
+			}
+		})
+	}
+}
+
+func TestTemplateMissingKeys(t *testing.T) {
+	_, err := Template("{{% domain %}} {{% .root %}} {{% if secure %}}{{% end %}}", TemplateData{"domain": "shop.test"})
+	if err == nil {
+		t.Fatal("Template succeeded with missing keys")
+	}
+
+	if !strings.Contains(err.Error(), "root, secure") {
+		t.Errorf("error %q does not name the missing keys", err)
+	}
+}
+
+func TestTemplateWithDefaults(t *testing.T) {
+	output, err := TemplateWithDefaults("{{% domain %}}:{{% port %}}", TemplateData{"domain": "shop.test"}, TemplateData{"domain": "localhost", "port": "80"})
+	if err != nil {
+		t.Fatalf("TemplateWithDefaults: %v", err)
+	}
+
+	if output != "shop.test:80" {
+		t.Errorf("TemplateWithDefaults = %q, want %q", output, "shop.test:80")
+	}
+}
+
+func TestValidateTemplateReservedKeys(t *testing.T) {
+	for _, key := range []string{"default", "join", "len", "printf", "if", "nil"} {
+		t.Run(key, func(t *testing.T) {
+			err := ValidateTemplate("{{% domain %}}", TemplateData{"domain": "shop.test", key: "value"})
+			if err == nil {
+				t.Fatalf("ValidateTemplate accepted the key %s", key)
+			}
+
+			if !strings.Contains(err.Error(), key) {
+				t.Errorf("error %q does not name %s", err, key)
+			}
+		})
+	}
+}
+
+func TestExtractTemplateKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "bare keys and fields",
+			content:  "{{% domain %}} {{% .root %}} {{% domain %}}",
+			expected: []string{"domain", "root"},
+		},
+		{
+			name:     "helpers and builtins are not keys",
+			content:  `{{% join " " aliases %}} {{% if eq (len aliases) 0 %}}{{% end %}} {{% default "x" php %}}`,
+			expected: []string{"aliases", "php"},
+		},
+		{
+			name:     "variables are not keys",
+			content:  "{{% $name := domain %}}{{% $name %}}",
+			expected: []string{"domain"},
+		},
+		{
+			name:     "fields inside range refer to the element",
+			content:  "{{% range .sites %}}{{% .root %}} {{% php %}}{{% end %}}",
+			expected: []string{"php", "sites"},
+		},
+		{
+			name:     "fields inside with refer to its value",
+			content:  "{{% with pool %}}{{% .name %}}{{% else %}}{{% .fallback %}}{{% end %}}",
+			expected: []string{"fallback", "pool"},
+		},
+		{
+			name:     "fields inside if refer to the data",
+			content:  "{{% if .secure %}}{{% .cert %}}{{% else %}}{{% .port %}}{{% end %}}",
+			expected: []string{"cert", "port", "secure"},
+		},
+		{
+			name:     "range else refers to the data",
+			content:  "{{% range aliases %}}{{% .host %}}{{% else %}}{{% .domain %}}{{% end %}}",
+			expected: []string{"aliases", "domain"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if keys := ExtractTemplateKeys(test.content); !slices.Equal(keys, test.expected) {
+				t.Errorf("ExtractTemplateKeys = %v, want %v", keys, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/lumosolutions/yerd/internal/constants"
+)
+
+var bundledTemplates fs.FS
+
+// SetBundledTemplates registers the config templates embedded in the
+// binary, so a release always renders the templates it was built with
+func SetBundledTemplates(templates fs.FS) {
+	bundledTemplates = templates
+}
+
+// LoadTemplate returns the content of a config template, a copy placed
+// in /opt/yerd/etc/templates takes precedence over the bundled template
+// folder: The template folder, eg: nginx, file: The template, eg: site.conf
+func LoadTemplate(folder, file string) (string, error) {
+	override := filepath.Join(constants.TemplatesDir, folder, file)
+	if FileExists(override) {
+		content, err := os.ReadFile(override)
+		if err != nil {
+			LogError(err, "templates")
+			return "", fmt.Errorf("unable to read %s: %w", override, err)
+		}
+
+		LogInfo("templates", "Using override %s", override)
+		return string(content), nil
+	}
+
+	if bundledTemplates == nil {
+		return "", fmt.Errorf("no templates are bundled with this build")
+	}
+
+	content, err := fs.ReadFile(bundledTemplates, path.Join(folder, file))
+	if err != nil {
+		LogError(err, "templates")
+		return "", fmt.Errorf("no template named %s/%s", folder, file)
+	}
+
+	return string(content), nil
+}
+
+// ListTemplates returns the names of the templates in a folder, both
+// bundled and in the override directory, sorted by name
+func ListTemplates(folder string) []string {
+	names := []string{}
+
+	if bundledTemplates != nil {
+		if entries, err := fs.ReadDir(bundledTemplates, folder); err == nil {
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					names = append(names, entry.Name())
+				}
+			}
+		}
+	}
+
+	if entries, err := os.ReadDir(filepath.Join(constants.TemplatesDir, folder)); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() && !slices.Contains(names, entry.Name()) {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return names
+}
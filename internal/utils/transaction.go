@@ -0,0 +1,57 @@
+package utils
+
+import "fmt"
+
+// Step is a single action within a Transaction, Undo is the compensating
+// action which reverses Do and is only run if Do succeeded
+type Step struct {
+	Name string
+	Do   func() error
+	Undo func() error
+}
+
+// Transaction runs a pipeline of steps, if any step fails then the undo
+// action of every completed step is run in reverse order so the system
+// is left as it was before the transaction started
+type Transaction struct {
+	context string
+	undo    []Step
+}
+
+// NewTransaction creates an empty transaction
+// context: Name used when logging, eg: addsite
+func NewTransaction(context string) *Transaction {
+	return &Transaction{context: context}
+}
+
+// Run executes steps in order, stopping and rolling back at the first failure
+func (t *Transaction) Run(steps ...Step) error {
+	for _, step := range steps {
+		if err := step.Do(); err != nil {
+			LogInfo(t.context, "Step '%s' failed: %v", step.Name, err)
+			t.Rollback()
+			return err
+		}
+
+		if step.Undo != nil {
+			t.undo = append(t.undo, step)
+		}
+	}
+
+	return nil
+}
+
+// Rollback runs every registered undo action in reverse order, errors
+// are logged so that one failure does not prevent the remaining undos
+func (t *Transaction) Rollback() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		step := t.undo[i]
+		LogInfo(t.context, "Rolling back '%s'", step.Name)
+
+		if err := step.Undo(); err != nil {
+			LogError(fmt.Errorf("unable to roll back '%s': %w", step.Name, err), t.context)
+		}
+	}
+
+	t.undo = nil
+}
@@ -93,3 +93,30 @@ func CheckInstallPermissions() error {
 
 	return nil
 }
+
+// GetFPMUser returns the username that should be used for PHP-FPM processes.
+// Uses the real user context (handling sudo scenarios) or falls back to "nobody".
+func GetFPMUser() string {
+	userCtx, err := GetRealUser()
+	if err != nil {
+		return "nobody"
+	}
+	return userCtx.Username
+}
+
+// GetFPMGroup returns the group name that should be used for PHP-FPM processes.
+// Uses the real user's primary group (handling sudo scenarios) or falls back to "nobody".
+func GetFPMGroup() string {
+	userCtx, err := GetRealUser()
+	if err != nil {
+		return "nobody"
+	}
+
+	// Get group information from GID
+	group, err := user.LookupGroupId(strconv.Itoa(userCtx.GID))
+	if err != nil {
+		return "nobody"
+	}
+
+	return group.Name
+}
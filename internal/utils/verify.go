@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned when a download does not match the
+// checksum published for it
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrBadSignature is returned when a download is not signed by a key in
+// the keyring used to verify it
+var ErrBadSignature = errors.New("signature verification failed")
+
+// VerifyChecksum compares the SHA-256 of the file at path with expected,
+// a hex encoded digest
+func VerifyChecksum(path, expected string) error {
+	expected = strings.ToLower(strings.TrimSpace(expected))
+	if expected == "" {
+		return fmt.Errorf("no checksum published for %s", path)
+	}
+
+	actual, err := FileChecksum(path)
+	if err != nil {
+		return err
+	}
+
+	if actual != expected {
+		LogWarning("verify", "%s has sha256 %s, expected %s", path, actual, expected)
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, path)
+	}
+
+	LogInfo("verify", "Verified sha256 of %s", path)
+	return nil
+}
+
+// FileChecksum returns the hex encoded SHA-256 of the file at path
+func FileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifySignature checks the detached signature of the file at path
+// against the keys in keyring using gpgv
+// path: The signed file, signature: The detached .asc signature
+func VerifySignature(path, signature, keyring string) error {
+	output, success := ExecuteCommand("gpgv", "--keyring", keyring, signature, path)
+	if !success {
+		LogWarning("verify", "gpgv rejected %s: %s", path, output)
+		return fmt.Errorf("%w: %s", ErrBadSignature, path)
+	}
+
+	LogInfo("verify", "Verified signature of %s", path)
+	return nil
+}
+
+// VerifyDownload checks a downloaded archive against its checksum, which
+// is required, and against its detached signature at signatureURL when
+// keyring exists, the archive is removed on failure
+// Returns whether the signature was checked
+func VerifyDownload(path, checksum, signatureURL, keyring string) (bool, error) {
+	if err := VerifyChecksum(path, checksum); err != nil {
+		os.Remove(path)
+		return false, err
+	}
+
+	if !FileExists(keyring) {
+		return false, nil
+	}
+
+	signature := path + ".asc"
+	defer os.Remove(signature)
+
+	if err := DownloadSource(signatureURL, signature); err != nil {
+		os.Remove(path)
+		return false, fmt.Errorf("unable to download the signature for %s: %w", path, err)
+	}
+
+	if err := VerifySignature(path, signature, keyring); err != nil {
+		ForgetSource(signatureURL)
+		os.Remove(path)
+		return false, err
+	}
+
+	return true, nil
+}
@@ -7,11 +7,21 @@ import (
 )
 
 const Version = "1.1.7"
-const Branch = "main"
-const Repo = "LumoSolutions/yerd"
+
+var splashDisabled bool
+
+// DisableSplash stops PrintSplash from printing, used when the output
+// of a command must only contain machine readable data
+func DisableSplash() {
+	splashDisabled = true
+}
 
 // PrintSplash displays the YERD ASCII art logo and version information with colors.
 func PrintSplash() {
+	if splashDisabled {
+		return
+	}
+
 	cyan := color.New(color.FgCyan, color.Bold)
 	yellow := color.New(color.FgYellow, color.Bold)
 	white := color.New(color.FgWhite)
@@ -44,16 +54,3 @@ func PrintSplash() {
 func GetVersion() string {
 	return Version
 }
-
-// GetBranch returns the current branch that files should
-// be downloaded from, useful during development, should
-// be reset to main on build for release
-func GetBranch() string {
-	return Branch
-}
-
-// GetRepo returns the current repo used to store files
-// and will be where the .config files are located
-func GetRepo() string {
-	return Repo
-}
@@ -1,7 +1,19 @@
 package main
 
-import "github.com/lumosolutions/yerd/cmd"
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/lumosolutions/yerd/cmd"
+	"github.com/lumosolutions/yerd/internal/utils"
+)
+
+//go:embed .config
+var configFiles embed.FS
 
 func main() {
+	templates, _ := fs.Sub(configFiles, ".config")
+	utils.SetBundledTemplates(templates)
+
 	cmd.Execute()
 }